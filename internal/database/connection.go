@@ -12,7 +12,8 @@ import (
 
 var DB *gorm.DB
 
-func Connect() {
+// DSN builds the connection string of the database from the environment
+func DSN() string {
 	// Load environment variables
 	dbHost := os.Getenv("DB_HOST")
 	dbPass := os.Getenv("DB_PASSWORD")
@@ -21,24 +22,31 @@ func Connect() {
 	dbName := os.Getenv("DB_NAME")
 
 	// Create the DSN (Data Source Name)
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable", dbHost, dbUser, dbPass, dbName, dbPort)
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable", dbHost, dbUser, dbPass, dbName, dbPort)
+}
 
+func Connect() {
 	// Connect to the database
 	var err error
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	DB, err = gorm.Open(postgres.Open(DSN()), &gorm.Config{})
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
 	fmt.Println("Database connection successfully established")
 
-	AutoMigrate(&models.User{}, &models.Post{}, &models.Like{}, &models.Comment{}, &models.Notification{}, &models.Actor{})
+	dropLegacyColumns()
+
+	AutoMigrate(&models.User{}, &models.Post{}, &models.Like{}, &models.Comment{}, &models.Notification{}, &models.Actor{}, &models.Follow{}, &models.Circle{}, &models.CircleMember{}, &models.PostRevision{}, &models.CommentRevision{}, &models.PostMedia{}, &models.PushOutbox{}, &models.NotificationDeliveryStats{}, &models.UserDevice{}, &models.NotificationPreferences{}, &models.MutedPost{}, &models.FeedEntry{})
+
+	normalizeShareStates()
 
 	err = DB.Exec("CREATE EXTENSION IF NOT EXISTS \"uuid-ossp\"").Error
 	if err != nil {
 		log.Fatal("Failed to enable uuid-ossp extension:", err)
 	}
 
+	movePushTokensToDevices()
 }
 
 func AutoMigrate(models ...interface{}) {
@@ -47,3 +55,62 @@ func AutoMigrate(models ...interface{}) {
 		log.Fatalf("AutoMigrate failed: %v", err)
 	}
 }
+
+// dropLegacyColumns removes columns whose type changed in a way AutoMigrate can't convert,
+// AutoMigrate then recreates them with the new type
+func dropLegacyColumns() {
+	// posts.mentioned_users was int[] and could never hold the numeric string user IDs, it is jsonb now
+	err := DB.Exec(`DO $$
+	BEGIN
+		IF EXISTS (SELECT 1 FROM information_schema.columns
+			WHERE table_name = 'posts' AND column_name = 'mentioned_users' AND data_type = 'ARRAY') THEN
+			ALTER TABLE posts DROP COLUMN mentioned_users;
+		END IF;
+	END $$`).Error
+	if err != nil {
+		log.Fatalf("Failed to drop legacy columns: %v", err)
+	}
+}
+
+// normalizeShareStates rewrites the share states of posts saved before they were validated to
+// their canonical spelling, "public" or "only_me" for example, so these posts keep their audience.
+// Posts without a share state were public, values that match no share state are left alone and
+// stay visible to their author only.
+func normalizeShareStates() {
+	err := DB.Exec(`UPDATE posts SET share_state = CASE lower(regexp_replace(COALESCE(share_state, ''), '[\s_-]', '', 'g'))
+			WHEN '' THEN @public
+			WHEN 'public' THEN @public
+			WHEN 'private' THEN @private
+			WHEN 'onlyme' THEN @only_me
+			WHEN 'circles' THEN @circles
+			ELSE share_state END
+		WHERE share_state IS NULL OR share_state NOT IN (@public, @private, @only_me, @circles)`,
+		map[string]interface{}{
+			"public":  models.ShareStatePublic,
+			"private": models.ShareStatePrivate,
+			"only_me": models.ShareStateOnlyMe,
+			"circles": models.ShareStateCircles,
+		}).Error
+	if err != nil {
+		log.Fatalf("Failed to normalize post share states: %v", err)
+	}
+}
+
+// movePushTokensToDevices moves the single push token users used to have to the user_devices
+// table, the platform of these devices is unknown until the app registers them again
+func movePushTokensToDevices() {
+	err := DB.Exec(`DO $$
+	BEGIN
+		IF EXISTS (SELECT 1 FROM information_schema.columns
+			WHERE table_name = 'users' AND column_name = 'push_token') THEN
+			INSERT INTO user_devices (id, user_id, token, platform, app_version, locale, last_seen_at, created_at, updated_at)
+			SELECT uuid_generate_v4(), id::text, push_token, '', '', COALESCE(user_lang, ''), updated_at, now(), now()
+			FROM users WHERE push_token IS NOT NULL AND push_token <> ''
+			ON CONFLICT (token) DO NOTHING;
+			ALTER TABLE users DROP COLUMN push_token;
+		END IF;
+	END $$`).Error
+	if err != nil {
+		log.Fatalf("Failed to move push tokens to devices: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RequireAdmin only lets admins through. The role is read from the database rather than from
+// the token so a revoked admin loses access right away.
+func RequireAdmin(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	user, err := storage.FindUserByID(userID)
+	if err != nil || user.Role != models.RoleAdmin {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Admin access required",
+		})
+	}
+
+	return c.Next()
+}
+
+// GetPushOutboxHandler lists the queued pushes of a status, dead pushes by default, with the
+// number of pushes in each status
+func GetPushOutboxHandler(c *fiber.Ctx) error {
+	limit, cursor, err := parsePagination(c)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// One extra push tells if there is a next page
+	pushes, err := services.FetchPushOutboxService(c.Query("status"), limit+1, cursor)
+	if errors.Is(err, services.ErrInvalidPushStatus) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid status, expected pending, sent, dead or skipped",
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch pushes",
+		})
+	}
+
+	counts, err := services.CountPushOutboxService()
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count pushes",
+		})
+	}
+
+	nextCursor := ""
+	if len(pushes) > limit {
+		pushes = pushes[:limit]
+		last := pushes[len(pushes)-1]
+		nextCursor = utils.EncodeCursor(last.UpdatedAt, last.ID)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"stop":        nextCursor == "",
+		"next_cursor": nextCursor,
+		"counts":      counts,
+		"pushes":      pushes,
+	})
+}
+
+// RequeuePushHandler puts a dead push back in the queue
+func RequeuePushHandler(c *fiber.Ctx) error {
+	pushID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid push ID",
+		})
+	}
+
+	err = services.RequeuePushService(pushID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "Dead push not found",
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to requeue push",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "Push queued again",
+	})
+}
+
+// GetNotificationDeliveryStatsHandler returns what happened to the pushes of a notification
+func GetNotificationDeliveryStatsHandler(c *fiber.Ctx) error {
+	notificationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid notification ID",
+		})
+	}
+
+	stats, err := services.FindDeliveryStatsService(notificationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "No push was queued for this notification",
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch delivery stats",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(stats)
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type circleRequestBody struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// GetCirclesHandler lists the circles owned by the authenticated user
+func GetCirclesHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	circles, err := storage.GetCirclesByUserID(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve circles",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"circles": circles,
+	})
+}
+
+// CreateCircleHandler creates a new circle for the authenticated user
+func CreateCircleHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	var requestBody circleRequestBody
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	circle, err := services.CreateCircleService(userID, requestBody.Name, requestBody.Description)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Circle created successfully",
+		"circle":  circle,
+	})
+}
+
+// UpdateCircleHandler renames a circle or changes its description
+func UpdateCircleHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	circleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid circle ID",
+		})
+	}
+
+	var requestBody circleRequestBody
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	circle, err := services.UpdateCircleService(circleID, userID, requestBody.Name, requestBody.Description)
+	if err != nil {
+		return circleErrorResponse(c, err, "Failed to update circle")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Circle updated successfully",
+		"circle":  circle,
+	})
+}
+
+// DeleteCircleHandler deletes a circle and its members
+func DeleteCircleHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	circleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid circle ID",
+		})
+	}
+
+	if err := services.DeleteCircleService(circleID, userID); err != nil {
+		return circleErrorResponse(c, err, "Failed to delete circle")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Circle deleted successfully",
+	})
+}
+
+// GetCircleMembersHandler pages through the members of a circle
+func GetCircleMembersHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	circleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid circle ID",
+		})
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid limit parameter",
+		})
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	// Only the owner can see who is in a circle
+	if _, err := services.FindOwnedCircle(circleID, userID); err != nil {
+		return circleErrorResponse(c, err, "Failed to find circle")
+	}
+
+	users, err := storage.GetCircleMembers(circleID, limit, (page-1)*limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve circle members",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"stop":  len(users) < limit, // true if no more data to load, false otherwise
+		"users": users,
+	})
+}
+
+// AddCircleMembersHandler adds one or more users to a circle
+func AddCircleMembersHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	circleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid circle ID",
+		})
+	}
+
+	var requestBody struct {
+		MemberIDs []string `json:"member_ids"`
+	}
+	if err := c.BodyParser(&requestBody); err != nil || len(requestBody.MemberIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if err := services.AddCircleMembersService(circleID, userID, requestBody.MemberIDs); err != nil {
+		return circleErrorResponse(c, err, "Failed to add circle members")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Circle members added successfully",
+	})
+}
+
+// RemoveCircleMemberHandler removes a user from a circle
+func RemoveCircleMemberHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	circleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid circle ID",
+		})
+	}
+
+	if err := services.RemoveCircleMemberService(circleID, userID, c.Params("memberId")); err != nil {
+		return circleErrorResponse(c, err, "Failed to remove circle member")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Circle member removed successfully",
+	})
+}
+
+// circleErrorResponse maps circle service errors to a response, missing circles become a 404
+// and unknown members a 400
+func circleErrorResponse(c *fiber.Ctx, err error, message string) error {
+	if errors.Is(err, services.ErrUnknownMember) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Circle not found",
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": message,
+	})
+}
@@ -1,14 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"strconv"
 
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models/requestModels"
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // DeleteComment deletes a comment from a post
@@ -77,6 +79,11 @@ func CreateComment(c *fiber.Ctx) error {
 
 	// Call the service to handle the comment creation
 	comment, err := services.CreateCommentService(uuidPostID, userID, requestBody, lang)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Post not found",
+		})
+	}
 	if err != nil {
 		log.Println("Error: Failed to create comment -", err) // Log the error
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -94,7 +101,7 @@ func CreateComment(c *fiber.Ctx) error {
 // FetchComments handles fetching all comments for a post with an optional limit
 func FetchComments(c *fiber.Ctx) error {
 	// Ensure the user is authenticated
-	_, err := ValidateRequest(c) // Assuming you have a method to validate the user
+	userID, err := ValidateRequest(c) // Assuming you have a method to validate the user
 	if err != nil {
 		log.Println("Error: Unauthorized user -", err) // Log the error
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -112,18 +119,22 @@ func FetchComments(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get the limit query parameter, if provided
-	limitParam := c.Query("limit", "10") // Default to 10 comments if limit not provided
-	limit, err := strconv.Atoi(limitParam)
-	if err != nil || limit <= 0 {
-		log.Println("Error: Invalid limit parameter -", err) // Log the error
+	// Get the limit and cursor query parameters, if provided
+	limit, cursor, err := parsePagination(c)
+	if err != nil {
+		log.Println("Error: Invalid pagination parameters -", err) // Log the error
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid limit parameter",
+			"error": err.Error(),
 		})
 	}
 
-	// Call the service to fetch the comments for the post with the limit
-	comments, err := services.FetchCommentsService(uuidPostID, limit)
+	// Call the service to fetch the comments for the post, one extra comment tells if there is a next page
+	comments, err := services.FetchCommentsService(uuidPostID, userID, limit+1, cursor)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Post not found",
+		})
+	}
 	if err != nil {
 		log.Println("Error: Failed to fetch comments for post ID:", postID, "-", err) // Log the error
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -131,12 +142,222 @@ func FetchComments(c *fiber.Ctx) error {
 		})
 	}
 
-	// Determine if "stop" should be true (i.e., when the number of comments fetched is less than the limit)
-	stop := len(comments) < limit
+	// Build the cursor of the next page when more comments are left
+	nextCursor := ""
+	if len(comments) > limit {
+		comments = comments[:limit]
+		last := comments[len(comments)-1]
+		nextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	// Return the list of comments, stop flag and next cursor in the response
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"stop":        nextCursor == "", // true if no more data to load, false otherwise
+		"next_cursor": nextCursor,       // pass back as 'cursor' to load the next page
+		"comments":    comments,         // the fetched comments
+	})
+}
+
+// CreateReply handles replying to a comment
+func CreateReply(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		log.Println("Error: Unauthorized user -", err) // Log the error
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	// Get the comment ID from the request parameters
+	lang := c.Get("Accept-Language", "en") // Default to "en" if not set
+	commentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		log.Println("Error: Invalid comment ID -", err) // Log the error
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid comment ID",
+		})
+	}
+
+	// Parse the request body to get the reply content
+	var requestBody requestModels.CreateCommentRequestBody
+	if err := c.BodyParser(&requestBody); err != nil {
+		log.Println("Error: Invalid request body -", err) // Log the error
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	// Call the service to handle the reply creation
+	reply, err := services.CreateReplyService(commentID, userID, requestBody, lang)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Comment not found",
+		})
+	}
+	if err != nil {
+		log.Println("Error: Failed to create reply -", err) // Log the error
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Return success response
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Reply created successfully",
+		"comment": reply,
+	})
+}
+
+// FetchReplies handles fetching the replies of a comment with an optional limit
+func FetchReplies(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		log.Println("Error: Unauthorized user -", err) // Log the error
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	// Get the comment ID from the request parameters
+	commentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		log.Println("Error: Invalid comment ID -", err) // Log the error
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid comment ID",
+		})
+	}
+
+	// Get the limit and cursor query parameters, if provided
+	limit, cursor, err := parsePagination(c)
+	if err != nil {
+		log.Println("Error: Invalid pagination parameters -", err) // Log the error
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Call the service to fetch the replies, one extra reply tells if there is a next page
+	replies, err := services.FetchRepliesService(commentID, userID, limit+1, cursor)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Comment not found",
+		})
+	}
+	if err != nil {
+		log.Println("Error: Failed to fetch replies for comment ID:", commentID, "-", err) // Log the error
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch replies",
+		})
+	}
+
+	// Build the cursor of the next page when more replies are left
+	nextCursor := ""
+	if len(replies) > limit {
+		replies = replies[:limit]
+		last := replies[len(replies)-1]
+		nextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	// Return the list of replies, stop flag and next cursor in the response
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"stop":        nextCursor == "", // true if no more data to load, false otherwise
+		"next_cursor": nextCursor,       // pass back as 'cursor' to load the next page
+		"replies":     replies,
+	})
+}
+
+// EditComment updates the content of a comment, only its author can edit it
+func EditComment(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	// Get the comment ID from the URL
+	commentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid comment ID",
+		})
+	}
+
+	var requestBody requestModels.EditCommentRequestBody
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	comment, err := services.EditCommentService(commentID, userID, requestBody)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Comment not found",
+		})
+	}
+	if errors.Is(err, services.ErrNotCommentAuthor) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if errors.Is(err, utils.ErrEmptyComment) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Println("Error: Failed to edit comment -", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to edit comment",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Comment updated successfully",
+		"comment": comment,
+	})
+}
+
+// GetCommentRevisions returns the previous versions of a comment to its author
+func GetCommentRevisions(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	// Get the comment ID from the URL
+	commentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid comment ID",
+		})
+	}
+
+	revisions, err := services.GetCommentRevisionsService(commentID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Comment not found",
+		})
+	}
+	if errors.Is(err, services.ErrNotCommentAuthor) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You are not authorized to view the history of this comment",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch comment revisions",
+		})
+	}
 
-	// Return the list of comments and stop flag in the response
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"stop":     stop,     // true if no more data to load, false otherwise
-		"comments": comments, // the fetched comments
+		"revisions": revisions,
 	})
 }
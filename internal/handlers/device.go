@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models/requestModels"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RegisterDeviceHandler registers the device the app runs on for push notifications
+func RegisterDeviceHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	var requestBody requestModels.RegisterDeviceRequestBody
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	device, err := services.RegisterDeviceService(userID, requestBody)
+	if errors.Is(err, services.ErrInvalidPushToken) || errors.Is(err, services.ErrInvalidPlatform) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to register device",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(device)
+}
+
+// GetDevicesHandler lists the devices of the user
+func GetDevicesHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	devices, err := services.GetUserDevicesService(userID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch devices",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"devices": devices,
+	})
+}
+
+// UnregisterDeviceHandler stops the pushes to one of the user's devices, on sign out for example
+func UnregisterDeviceHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	deviceID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid device ID",
+		})
+	}
+
+	err = services.UnregisterDeviceService(deviceID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "Device not found",
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to unregister device",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "Device unregistered",
+	})
+}
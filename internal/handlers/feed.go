@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetHomeFeed returns a page of the authenticated user's ranked home feed
+func GetHomeFeed(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	// Get the limit and cursor from query parameters, limit defaults to 10 if not provided
+	limit, cursor, err := parsePagination(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Fetch the ranked posts, one extra post tells if there is a next page
+	posts, asOf, err := services.HomeFeedService(userID, limit+1, cursor)
+	if err != nil {
+		log.Println("Error: Failed to fetch home feed -", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve feed",
+		})
+	}
+
+	// Build the cursor of the next page when more posts are left
+	nextCursor := ""
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[len(posts)-1]
+		nextCursor = utils.EncodeScoredCursor(asOf, last.Score, last.ID)
+	}
+
+	// Set 'YourLike' for each post and for the original of each reshare
+	if err := setPostsYourLike(posts, userID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve likes",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"stop":        nextCursor == "", // true if no more data to load, false otherwise
+		"next_cursor": nextCursor,       // pass back as 'cursor' to load the next page
+		"posts":       posts,
+	})
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// FollowUserHandler makes the authenticated user follow the user in the URL
+func FollowUserHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	followingID := c.Params("id")
+	if followingID == userID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "You cannot follow yourself",
+		})
+	}
+
+	if err := services.FollowUser(userID, followingID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "User not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to follow user",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":      "User followed successfully",
+		"is_following": true,
+	})
+}
+
+// UnfollowUserHandler makes the authenticated user stop following the user in the URL
+func UnfollowUserHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	if err := services.UnfollowUser(userID, c.Params("id")); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to unfollow user",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":      "User unfollowed successfully",
+		"is_following": false,
+	})
+}
+
+// GetFollowersHandler pages through the users following the user in the URL
+func GetFollowersHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	_, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid limit parameter",
+		})
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	users, err := storage.GetFollowers(c.Params("id"), limit, (page-1)*limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve followers",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"stop":  len(users) < limit, // true if no more data to load, false otherwise
+		"users": users,
+	})
+}
+
+// GetFollowingHandler pages through the users followed by the user in the URL
+func GetFollowingHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	_, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid limit parameter",
+		})
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	users, err := storage.GetFollowing(c.Params("id"), limit, (page-1)*limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve following",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"stop":  len(users) < limit, // true if no more data to load, false otherwise
+		"users": users,
+	})
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/url"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetHashtagPosts pages through the posts carrying the hashtag in the URL
+func GetHashtagPosts(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	// Arabic hashtags arrive percent-encoded in the path
+	rawHashtag, err := url.PathUnescape(c.Params("tag"))
+	if err != nil {
+		rawHashtag = c.Params("tag")
+	}
+	hashtag := utils.NormalizeHashtag(rawHashtag)
+	if hashtag == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid hashtag",
+		})
+	}
+
+	// Get the limit and cursor from query parameters, limit defaults to 10 if not provided
+	limit, cursor, err := parsePagination(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Fetch posts from the database, one extra post tells if there is a next page
+	posts, err := storage.GetPostsByHashtag(hashtag, userID, limit+1, cursor)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve posts",
+		})
+	}
+
+	// Build the cursor of the next page when more posts are left
+	nextCursor := ""
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[len(posts)-1]
+		nextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	// Set 'YourLike' for each post and for the original of each reshare
+	if err := setPostsYourLike(posts, userID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to retrieve likes",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"stop":        nextCursor == "", // true if no more data to load, false otherwise
+		"next_cursor": nextCursor,       // pass back as 'cursor' to load the next page
+		"hashtag":     hashtag,
+		"posts":       posts,
+	})
+}
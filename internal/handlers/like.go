@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
@@ -28,11 +29,11 @@ func LikePost(c *fiber.Ctx) error {
 		})
 	}
 
-	// Fetch the post from the database
-	post, err := storage.GetPostByID(uuid)
+	// Fetch the post from the database, posts the user can't see are reported as not found
+	post, err := storage.GetPostByIDForViewer(uuid, userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to find post",
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Post not found",
 		})
 	}
 
@@ -51,3 +52,53 @@ func LikePost(c *fiber.Ctx) error {
 		"liked":       liked,
 	})
 }
+
+// LikeComment toggles the like state of a comment for the authenticated user
+func LikeComment(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	// Get the comment ID from the URL parameters
+	lang := c.Get("Accept-Language", "en") // Default to "en" if not set
+	commentID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid comment ID",
+		})
+	}
+
+	// Fetch the comment from the database
+	comment, err := storage.FindCommentByID(commentID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Comment not found",
+		})
+	}
+
+	// Comments on posts the user can't see are reported as not found
+	if _, err := storage.GetPostByIDForViewer(comment.PostID, userID); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Comment not found",
+		})
+	}
+
+	// Toggle the like state based on the user
+	liked, err := services.ToggleCommentLike(comment, userID, lang)
+	if err != nil {
+		log.Println("Error: Failed to toggle comment like -", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update like status",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":     "Comment like state updated successfully",
+		"likes_count": comment.LikesCount,
+		"liked":       liked,
+	})
+}
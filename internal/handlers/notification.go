@@ -1,13 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	"strconv"
 
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
-	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // FetchNotificationsHandler handles the request for fetching user notifications
@@ -23,51 +24,65 @@ func FetchNotificationsHandler(c *fiber.Ctx) error {
 	// Access the Accept-Language header
 	lang := c.Get("Accept-Language", "en") // Default to "en" if not set
 
-	// Optional: parse the 'limit' query parameter (default to 10 if not provided)
-	limitQuery := c.Query("limit", "10")
-	limit, err := strconv.Atoi(limitQuery)
+	// Optional: parse the 'limit' (default to 10 if not provided) and 'cursor' query parameters
+	limit, cursor, err := parsePagination(c)
 	if err != nil {
 		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid limit parameter",
+			"error": err.Error(),
 		})
 	}
 
-	// Fetch the notifications using the service function
-	notifications, err := services.FetchUserNotificationsService(userID, limit, lang) // Pass lang to the service
+	// Fetch the notifications using the service function, one extra notification tells if there is a next page
+	notifications, err := services.FetchUserNotificationsService(userID, limit+1, cursor, lang) // Pass lang to the service
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to fetch notifications",
 		})
 	}
 
+	// Build the cursor of the next page when more notifications are left
+	nextCursor := ""
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+		last := notifications[len(notifications)-1]
+		nextCursor = utils.EncodeCursor(last.UpdatedAt, last.ID)
+	}
+
 	// Respond with the notifications
 	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"stop":          nextCursor == "", // true if no more data to load, false otherwise
+		"next_cursor":   nextCursor,       // pass back as 'cursor' to load the next page
 		"notifications": notifications,
 	})
 }
 
+// MarkNotificationsAsReadHandler marks one of the user's notifications as read
 func MarkNotificationsAsReadHandler(c *fiber.Ctx) error {
 	// Extract the userID from the request context (assuming it's set by middleware)
-	_, err := ValidateRequest(c)
+	userID, err := ValidateRequest(c)
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Unauthorized user",
 		})
 	}
 
-	notificationID := c.Params("id")
-	uuid, err := uuid.Parse(notificationID)
+	notificationID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid post ID",
+			"error": "Invalid notification ID",
 		})
 	}
 
-	// Fetch the notifications using the service function
-	err = storage.MarkNotificationAsRead(uuid) // Pass lang to the service
+	// Only the user's own notifications can be updated, others are reported as not found
+	err = services.MarkNotificationAsReadService(notificationID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "Notification not found",
+		})
+	}
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch notifications",
+			"error": "Failed to update notification",
 		})
 	}
 
@@ -76,3 +91,154 @@ func MarkNotificationsAsReadHandler(c *fiber.Ctx) error {
 		"message": "Notification marked as read",
 	})
 }
+
+// MarkNotificationsAsReadBulkHandler marks the listed notifications of the user as read
+func MarkNotificationsAsReadBulkHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	var requestBody struct {
+		IDs []string `json:"ids"`
+	}
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	notificationIDs := make([]uuid.UUID, 0, len(requestBody.IDs))
+	for _, id := range requestBody.IDs {
+		notificationID, err := uuid.Parse(id)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid notification ID: " + id,
+			})
+		}
+		notificationIDs = append(notificationIDs, notificationID)
+	}
+
+	updated, err := services.MarkNotificationsAsReadService(userID, notificationIDs)
+	if errors.Is(err, services.ErrTooManyNotifications) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update notifications",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "Notifications marked as read",
+		"updated": updated,
+	})
+}
+
+// MarkAllNotificationsAsReadHandler marks every notification of the user as read
+func MarkAllNotificationsAsReadHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	updated, err := services.MarkAllNotificationsAsReadService(userID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update notifications",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "All notifications marked as read",
+		"updated": updated,
+	})
+}
+
+// GetUnreadNotificationsCountHandler returns how many notifications of the user are unread
+func GetUnreadNotificationsCountHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	count, err := services.CountUnreadNotificationsService(userID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count unread notifications",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"unread_count": count,
+	})
+}
+
+// DeleteNotificationHandler deletes one of the user's notifications
+func DeleteNotificationHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	notificationID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid notification ID",
+		})
+	}
+
+	// Only the user's own notifications can be deleted, others are reported as not found
+	err = services.DeleteNotificationService(notificationID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "Notification not found",
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete notification",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "Notification deleted",
+	})
+}
+
+// DeleteAllNotificationsHandler deletes every notification of the user
+func DeleteAllNotificationsHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	deleted, err := services.DeleteAllNotificationsService(userID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete notifications",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "All notifications deleted",
+		"deleted": deleted,
+	})
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models/requestModels"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetNotificationPreferencesHandler returns the notification preferences of the user
+func GetNotificationPreferencesHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	preferences, err := services.GetNotificationPreferencesService(userID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch notification preferences",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(preferences)
+}
+
+// UpdateNotificationPreferencesHandler changes the notification preferences of the user
+func UpdateNotificationPreferencesHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	var requestBody requestModels.UpdateNotificationPreferencesRequestBody
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	preferences, err := services.UpdateNotificationPreferencesService(userID, requestBody)
+	if errors.Is(err, services.ErrInvalidActionType) || errors.Is(err, services.ErrInvalidQuietHours) || errors.Is(err, services.ErrInvalidTimezone) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update notification preferences",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(preferences)
+}
+
+// MutePostHandler stops the notifications about a post for the user
+func MutePostHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	postID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid post ID",
+		})
+	}
+
+	// Posts the user can't see are reported as not found
+	err = services.MutePostService(postID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "Post not found",
+		})
+	}
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to mute post",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "Post muted",
+		"muted":   true,
+	})
+}
+
+// UnmutePostHandler brings back the notifications about a post for the user
+func UnmutePostHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	postID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid post ID",
+		})
+	}
+
+	if err := services.UnmutePostService(postID, userID); err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to unmute post",
+		})
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"message": "Post unmuted",
+		"muted":   false,
+	})
+}
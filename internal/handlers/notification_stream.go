@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/realtime"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// streamHeartbeat keeps idle connections open through proxies and detects closed clients
+const streamHeartbeat = 15 * time.Second
+
+// StreamNotificationsHandler streams the user's notification events as Server-Sent Events. The
+// current unread count is sent first, then every new or updated notification and every change
+// of the unread count.
+func StreamNotificationsHandler(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	// Access the Accept-Language header
+	lang := c.Get("Accept-Language", "en") // Default to "en" if not set
+
+	unreadCount, err := services.CountUnreadNotificationsService(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count unread notifications",
+		})
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // Disable response buffering in nginx
+
+	events, unsubscribe := realtime.LocalHub.Subscribe(userID)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		heartbeat := time.NewTicker(streamHeartbeat)
+		defer heartbeat.Stop()
+
+		if err := writeServerSentEvent(w, realtime.EventUnreadCount, fiber.Map{"unread_count": unreadCount}); err != nil {
+			return
+		}
+
+		for {
+			select {
+			case event := <-events:
+				if err := writeNotificationEvent(w, event, lang); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := w.WriteString(": ping\n\n"); err != nil {
+					return
+				}
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	})
+
+	return nil
+}
+
+// writeNotificationEvent sends a hub event to the client, notifications are loaded and
+// localized for the connection
+func writeNotificationEvent(w *bufio.Writer, event realtime.Event, lang string) error {
+	if event.Type == realtime.EventNotification {
+		notificationID, err := uuid.Parse(event.NotificationID)
+		if err != nil {
+			return nil
+		}
+
+		// The notification may have been deleted since the event was published
+		notification, err := services.FindUserNotificationService(notificationID, event.UserID, lang)
+		if err == nil {
+			if err := writeServerSentEvent(w, realtime.EventNotification, notification); err != nil {
+				return err
+			}
+		} else {
+			log.Println("Error loading streamed notification:", err)
+		}
+	}
+
+	return writeServerSentEvent(w, realtime.EventUnreadCount, fiber.Map{"unread_count": event.UnreadCount})
+}
+
+// writeServerSentEvent writes one event and flushes it to the client
+func writeServerSentEvent(w *bufio.Writer, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, payload); err != nil {
+		return err
+	}
+	return w.Flush()
+}
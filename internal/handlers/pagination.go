@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
+	"github.com/gofiber/fiber/v2"
+)
+
+// parsePagination reads the 'limit' and 'cursor' query parameters, limit defaults to 10
+func parsePagination(c *fiber.Ctx) (int, *utils.Cursor, error) {
+	limit, err := strconv.Atoi(c.Query("limit", "10"))
+	if err != nil || limit <= 0 {
+		return 0, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid limit parameter")
+	}
+
+	cursor, err := utils.DecodeCursor(c.Query("cursor"))
+	if err != nil {
+		return 0, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid cursor parameter")
+	}
+
+	return limit, cursor, nil
+}
@@ -1,20 +1,70 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
-	"mime/multipart"
-	"path/filepath"
-	"strconv"
+	"log"
+	"slices"
+	"strings"
 
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/media"
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+// absoluteMediaURL resolves the server relative URLs returned by the local media store
+func absoluteMediaURL(c *fiber.Ctx, mediaURL string) string {
+	if strings.HasPrefix(mediaURL, "/") {
+		return c.BaseURL() + mediaURL
+	}
+	return mediaURL
+}
+
+// absolutePostMedia resolves the URLs of the attachments and of their variants
+func absolutePostMedia(c *fiber.Ctx, postMedia []models.PostMedia) []models.PostMedia {
+	for i := range postMedia {
+		postMedia[i].URL = absoluteMediaURL(c, postMedia[i].URL)
+		for name, variant := range postMedia[i].Variants {
+			variant.URL = absoluteMediaURL(c, variant.URL)
+			postMedia[i].Variants[name] = variant
+		}
+	}
+	return postMedia
+}
+
+// mediaErrorResponse reports a failed upload, invalid images and videos are the client's fault
+func mediaErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrTooManyMedia):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, media.ErrImageTooLarge), errors.Is(err, media.ErrVideoTooLarge):
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, media.ErrVideoTooLong):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, media.ErrUnsupportedImage), errors.Is(err, media.ErrUnsupportedVideo):
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		log.Println("Error: Failed to save media -", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to save media",
+		})
+	}
+}
+
 // Helper function for checking user authentication
 func ValidateRequest(c *fiber.Ctx) (string, error) {
 	user := c.Locals("user").(*jwt.Token)
@@ -28,6 +78,40 @@ func ValidateRequest(c *fiber.Ctx) (string, error) {
 	return id, nil
 }
 
+// setPostsYourLike fetches the user's likes on the posts in bulk and sets 'YourLike' on each
+// post and on the original of each reshare
+func setPostsYourLike(posts []models.Post, userID string) error {
+	var postIDs []uuid.UUID
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+		if post.ResharedPost != nil {
+			postIDs = append(postIDs, post.ResharedPost.ID)
+		}
+	}
+	if len(postIDs) == 0 {
+		return nil
+	}
+
+	var likes []models.Like
+	err := database.DB.Where("user_id = ? AND comment_id IS NULL AND post_id IN ?", userID, postIDs).Find(&likes).Error
+	if err != nil {
+		return err
+	}
+
+	likedPostIDs := make(map[uuid.UUID]bool)
+	for _, like := range likes {
+		likedPostIDs[like.PostID] = true
+	}
+
+	for i := range posts {
+		posts[i].YourLike = likedPostIDs[posts[i].ID]
+		if posts[i].ResharedPost != nil {
+			posts[i].ResharedPost.YourLike = likedPostIDs[posts[i].ResharedPost.ID]
+		}
+	}
+	return nil
+}
+
 func DeletePost(c *fiber.Ctx) error {
 	// Ensure the user is authenticated
 	userID, err := ValidateRequest(c)
@@ -68,6 +152,13 @@ func DeletePost(c *fiber.Ctx) error {
 		})
 	}
 
+	// Delete the edit history of the comments
+	if err := storage.DeleteCommentRevisionsByPostID(postUUID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete comment revisions",
+		})
+	}
+
 	// Delete all associated comments for the post
 	if err := storage.DeleteCommentsByPostID(postUUID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -75,12 +166,62 @@ func DeletePost(c *fiber.Ctx) error {
 		})
 	}
 
+	// Unlink the reshares of the post so they survive as standalone posts
+	if err := storage.DetachReshares(postUUID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to detach post reshares",
+		})
+	}
+
+	// Delete the edit history of the post, the files of earlier versions go with the post
+	revisions, err := storage.GetPostRevisions(postUUID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch post revisions",
+		})
+	}
+	if err := storage.DeletePostRevisions(postUUID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete post revisions",
+		})
+	}
+
+	// Delete the attachments of the post, their files go once the post is gone
+	postMedia, err := storage.GetPostMedia(postUUID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch post media",
+		})
+	}
+	if err := storage.DeletePostMedia(postUUID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete post media",
+		})
+	}
+
+	// Delete the mutes of the post
+	if err := storage.DeleteMutedPostsByPostID(postUUID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete post mutes",
+		})
+	}
+
 	// Delete the post itself
 	if err := storage.DeletePost(postUUID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete post",
 		})
 	}
+	services.DeleteMediaFiles(services.PostMediaURLs(post, postMedia, revisions))
+
+	// Decrement the reshares counter of the original post when deleting a reshare
+	if post.ResharedPostID != nil {
+		if err := storage.UpdatePostResharesCount(*post.ResharedPostID, -1); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update reshares counter",
+			})
+		}
+	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": fmt.Sprintf("Post deleted successfully by user %s", userID),
@@ -105,8 +246,8 @@ func GetPostByID(c *fiber.Ctx) error {
 		})
 	}
 
-	// Fetch the post from the database
-	post, err := storage.GetPostByID(uuid)
+	// Fetch the post from the database, posts the user can't see are reported as not found
+	post, err := storage.GetPostDetailsForViewer(uuid, userID)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Post not found",
@@ -115,7 +256,7 @@ func GetPostByID(c *fiber.Ctx) error {
 
 	// Check if the user has liked the post
 	var like models.Like
-	err = database.DB.Where("post_id = ? AND user_id = ?", post.ID, userID).First(&like).Error
+	err = database.DB.Where("post_id = ? AND user_id = ? AND comment_id IS NULL", post.ID, userID).First(&like).Error
 	if err == nil {
 		// User has liked the post
 		post.YourLike = true
@@ -123,6 +264,12 @@ func GetPostByID(c *fiber.Ctx) error {
 		post.YourLike = false
 	}
 
+	// Check if the user has liked the original post of a reshare
+	if post.ResharedPost != nil {
+		err = database.DB.Where("post_id = ? AND user_id = ? AND comment_id IS NULL", post.ResharedPost.ID, userID).First(&like).Error
+		post.ResharedPost.YourLike = err == nil
+	}
+
 	// Return the post with the 'YourLike' field included
 	return c.Status(fiber.StatusOK).JSON(post)
 }
@@ -136,52 +283,42 @@ func GetPosts(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get the limit from query parameters, default to 10 if not provided
-	limitParam := c.Query("limit", "10")
-	limit, err := strconv.Atoi(limitParam)
-	if err != nil || limit <= 0 {
+	// Get the limit and cursor from query parameters, limit defaults to 10 if not provided
+	limit, cursor, err := parsePagination(c)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid limit parameter",
+			"error": err.Error(),
 		})
 	}
 
-	// Fetch posts from the database with the specified limit
-	posts, err := storage.GetPosts(limit)
+	// Fetch posts from the database, one extra post tells if there is a next page
+	posts, err := storage.GetPosts(userID, limit+1, cursor)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve posts",
 		})
 	}
 
-	// Fetch the likes for the current user in bulk
-	var likes []models.Like
-	err = database.DB.Where("user_id = ?", userID).Find(&likes).Error
-	if err != nil {
+	// Build the cursor of the next page when more posts are left
+	nextCursor := ""
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[len(posts)-1]
+		nextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	// Set 'YourLike' for each post and for the original of each reshare
+	if err := setPostsYourLike(posts, userID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve likes",
 		})
 	}
 
-	likedPostIDs := make(map[uuid.UUID]bool)
-	for _, like := range likes {
-		likedPostIDs[like.PostID] = true
-	}
-
-	// Set 'YourLike' for each post
-	for i := range posts {
-		if likedPostIDs[posts[i].ID] {
-			posts[i].YourLike = true
-		} else {
-			posts[i].YourLike = false
-		}
-	}
-
-	stop := len(posts) < limit
-
 	// Return the posts as JSON
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"stop":  stop,  // true if no more data to load, false otherwise
-		"posts": posts, // the fetched posts
+		"stop":        nextCursor == "", // true if no more data to load, false otherwise
+		"next_cursor": nextCursor,       // pass back as 'cursor' to load the next page
+		"posts":       posts,            // the fetched posts
 	})
 }
 
@@ -194,59 +331,49 @@ func GetPostsByUserIdHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Get the limit from query parameters, default to 10 if not provided
-	limitParam := c.Query("limit", "10")
+	// Get the limit and cursor from query parameters, limit defaults to 10 if not provided
 	requestedUserID := c.Params("id")
-	limit, err := strconv.Atoi(limitParam)
-	if err != nil || limit <= 0 {
+	limit, cursor, err := parsePagination(c)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid limit parameter",
+			"error": err.Error(),
 		})
 	}
 
-	// Fetch posts from the database with the specified limit
-	posts, err := storage.GetPostsByUserID(requestedUserID, limit)
+	// Fetch posts from the database, one extra post tells if there is a next page
+	posts, err := storage.GetPostsByUserID(requestedUserID, userID, limit+1, cursor)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve posts",
 		})
 	}
 
-	// Fetch the likes for the current user in bulk
-	var likes []models.Like
-	err = database.DB.Where("user_id = ?", userID).Find(&likes).Error
-	if err != nil {
+	// Build the cursor of the next page when more posts are left
+	nextCursor := ""
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[len(posts)-1]
+		nextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+
+	// Set 'YourLike' for each post and for the original of each reshare
+	if err := setPostsYourLike(posts, userID); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to retrieve likes",
 		})
 	}
 
-	likedPostIDs := make(map[uuid.UUID]bool)
-	for _, like := range likes {
-		likedPostIDs[like.PostID] = true
-	}
-
-	// Set 'YourLike' for each post
-	for i := range posts {
-		if likedPostIDs[posts[i].ID] {
-			posts[i].YourLike = true
-		} else {
-			posts[i].YourLike = false
-		}
-	}
-
-	stop := len(posts) < limit
-
 	// Return the posts as JSON
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"stop":  stop,  // true if no more data to load, false otherwise
-		"posts": posts, // the fetched posts
+		"stop":        nextCursor == "", // true if no more data to load, false otherwise
+		"next_cursor": nextCursor,       // pass back as 'cursor' to load the next page
+		"posts":       posts,            // the fetched posts
 	})
 }
 
 func CreatePost(c *fiber.Ctx) error {
 	// Ensure the user is authenticated
-	_, err := ValidateRequest(c)
+	userID, err := ValidateRequest(c)
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Unauthorized user",
@@ -269,29 +396,46 @@ func CreatePost(c *fiber.Ctx) error {
 		})
 	}
 
-	// Handle image upload if present
-	var fileHeader *multipart.FileHeader
-	if files := form.File["image_url"]; len(files) > 0 {
-		fileHeader = files[0]
-		imagePath, err := services.SaveImage(fileHeader)
+	// Make sure the post only targets the author's own circles
+	if err := services.ValidatePostCircles(post, userID); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// Resolve the users mentioned in the body or sent as a structured field
+	post.MentionedUsers, err = services.ResolveMentions(userID, post.Body, post.MentionedUsers)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to resolve mentioned users",
+		})
+	}
+
+	// The preview of the first link of the body is fetched once the post is saved
+	previewLink := services.AttachLinkPreview(post)
+
+	// Handle the uploaded images and videos, image_url is the single image field of older clients
+	if files := slices.Concat(form.File["image_url"], form.File["media"]); len(files) > 0 {
+		postMedia, err := services.SavePostMedia(files, form.Value["media_alt_text"])
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to save image",
-			})
+			return mediaErrorResponse(c, err)
 		}
-
-		// Set the full image URL in the post struct
-		post.ImageURL = fmt.Sprintf("%s/uploads/%s", c.BaseURL(), filepath.Base(imagePath))
+		services.SetPostMedia(post, absolutePostMedia(c, postMedia))
 	} else {
 		post.ImageURL = ""
 	}
 
 	// Create the post in the database
 	if err := storage.CreatePost(*post); err != nil {
+		services.DiscardPostMedia(post.Media)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Could not create post",
 		})
 	}
+	services.FetchLinkPreview(post, previewLink)
+
+	// Notify the mentioned users
+	services.NotifyPostMentions(post, post.MentionedUsers)
 
 	return c.Status(fiber.StatusCreated).JSON(post)
 }
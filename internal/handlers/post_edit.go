@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"slices"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EditPost updates the body, image or audience of a post, only its author can edit it
+func EditPost(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	// Get the post ID from the URL parameters
+	postID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid post ID",
+		})
+	}
+
+	// Posts the user can't see are reported as not found, before telling them who the author is
+	current, err := storage.GetPostByIDForViewer(postID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Post not found",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch post",
+		})
+	}
+
+	// Only the author may upload new attachments
+	if current.AuthorID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": services.ErrNotPostAuthor.Error(),
+		})
+	}
+
+	// Parse form data
+	form, err := c.MultipartForm()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Cannot parse form data",
+		})
+	}
+
+	edit, err := services.ParsePostEditForm(form)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// New images and videos replace the current attachments
+	if files := slices.Concat(form.File["image_url"], form.File["media"]); len(files) > 0 {
+		postMedia, err := services.SavePostMedia(files, form.Value["media_alt_text"])
+		if err != nil {
+			return mediaErrorResponse(c, err)
+		}
+		edit.Media = absolutePostMedia(c, postMedia)
+		edit.ReplaceMedia = true
+	}
+
+	post, err := services.EditPostService(postID, userID, edit)
+	if err != nil {
+		// The new uploads were not attached to the post
+		services.DiscardPostMedia(edit.Media)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Post not found",
+		})
+	}
+	if errors.Is(err, services.ErrNotPostAuthor) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if errors.Is(err, services.ErrInvalidPostCircles) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Println("Error: Failed to edit post -", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to edit post",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(post)
+}
+
+// GetPostRevisions returns the previous versions of a post to its author
+func GetPostRevisions(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	// Get the post ID from the URL parameters
+	postID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid post ID",
+		})
+	}
+
+	revisions, err := services.GetPostRevisionsService(postID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Post not found",
+		})
+	}
+	if errors.Is(err, services.ErrNotPostAuthor) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You are not authorized to view the history of this post",
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch post revisions",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"revisions": revisions,
+	})
+}
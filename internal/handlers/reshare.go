@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ResharePost reshares an existing post with optional commentary
+func ResharePost(c *fiber.Ctx) error {
+	// Ensure the user is authenticated
+	userID, err := ValidateRequest(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized user",
+		})
+	}
+
+	// Get the post ID from the URL parameters
+	postID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid post ID",
+		})
+	}
+
+	var requestBody struct {
+		ShareText  string   `json:"share_text"`
+		ShareState string   `json:"share_state"`
+		CircleIDs  []string `json:"circle_ids"`
+	}
+	if err := c.BodyParser(&requestBody); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	reshare, err := services.ResharePostService(postID, userID, requestBody.ShareText, requestBody.ShareState, requestBody.CircleIDs)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Post not found",
+		})
+	}
+	if errors.Is(err, services.ErrReshareNotAllowed) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Println("Error: Failed to reshare post -", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(reshare)
+}
@@ -1,15 +1,19 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models/requestModels"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
 	"github.com/gofiber/fiber/v2"
 )
 
-// UpdatePushTokenHandler handles the request for updating a user's push token.
+// UpdatePushTokenHandler handles the request for updating a user's push token. It is kept for
+// older apps and registers the token as one of the user's devices, other devices keep theirs.
 func UpdatePushTokenHandler(c *fiber.Ctx) error {
 	// Extract user ID from the request parameters
 	userID, err := ValidateRequest(c)
@@ -38,15 +42,32 @@ func UpdatePushTokenHandler(c *fiber.Ctx) error {
 		})
 	}
 
-	// Update the user's push token
-	user.PushToken = requestBody.PushToken
-	user.UserLang = requestBody.UserLang
+	// Update the user's language
+	if requestBody.UserLang != "" && requestBody.UserLang != user.UserLang {
+		user.UserLang = requestBody.UserLang
+		if err := storage.UpdateUser(*user); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update push token",
+			})
+		}
+	}
 
-	// Save the updated user record in the database
-	if err := storage.UpdateUser(*user); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update push token",
+	// Register the token as a device of the user
+	if requestBody.PushToken != "" {
+		_, err := services.RegisterDeviceService(userID, requestModels.RegisterDeviceRequestBody{
+			Token:  requestBody.PushToken,
+			Locale: requestBody.UserLang,
 		})
+		if errors.Is(err, services.ErrInvalidPushToken) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to update push token",
+			})
+		}
 	}
 
 	// Respond with a success message
@@ -57,7 +78,7 @@ func UpdatePushTokenHandler(c *fiber.Ctx) error {
 
 func GetTheUser(c *fiber.Ctx) error {
 	// Ensure the user is authenticated
-	_, err := ValidateRequest(c)
+	userID, err := ValidateRequest(c)
 	if err != nil {
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Unauthorized user",
@@ -75,8 +96,33 @@ func GetTheUser(c *fiber.Ctx) error {
 		})
 	}
 
+	// Fetch the follow counters and whether the caller follows this user
+	followersCount, err := storage.CountFollowers(user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count followers",
+		})
+	}
+
+	followingCount, err := storage.CountFollowing(user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to count following",
+		})
+	}
+
+	isFollowing, err := services.IsFollowing(userID, user.ID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to check follow state",
+		})
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"user": user,
+		"user":            user,
+		"followers_count": followersCount,
+		"following_count": followingCount,
+		"is_following":    isFollowing,
 	})
 }
 
@@ -0,0 +1,174 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+)
+
+// Fetcher builds the preview of a link
+type Fetcher interface {
+	Fetch(ctx context.Context, link string) (*models.LinkPreview, error)
+}
+
+var (
+	ErrBlockedAddress = errors.New("link points to a private or reserved address")
+	ErrNotHTML        = errors.New("link is not an HTML page")
+)
+
+// HTTPFetcher downloads the page of a link and reads its metadata. Connections are only made
+// to public addresses, the check runs on the resolved IP of every connection so redirects and
+// DNS rebinding can't reach the internal network.
+type HTTPFetcher struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+// Options tunes an HTTPFetcher, zero values use the defaults
+type Options struct {
+	Timeout      time.Duration // Whole request, redirects included
+	MaxBytes     int64         // Largest part of the page read
+	MaxRedirects int
+	// AllowAddress decides which resolved addresses can be dialed, only public unicast
+	// addresses by default. Tests pointing to a local stand-in can relax it.
+	AllowAddress func(addr netip.Addr) bool
+}
+
+const (
+	defaultTimeout      = 5 * time.Second
+	defaultMaxBytes     = 512 << 10
+	defaultMaxRedirects = 3
+)
+
+// NewHTTPFetcher creates a fetcher with the given options
+func NewHTTPFetcher(options Options) *HTTPFetcher {
+	if options.Timeout <= 0 {
+		options.Timeout = defaultTimeout
+	}
+	if options.MaxBytes <= 0 {
+		options.MaxBytes = defaultMaxBytes
+	}
+	if options.MaxRedirects <= 0 {
+		options.MaxRedirects = defaultMaxRedirects
+	}
+	if options.AllowAddress == nil {
+		options.AllowAddress = IsPublicAddress
+	}
+
+	dialer := &net.Dialer{
+		Timeout: options.Timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !options.AllowAddress(addrPort.Addr().Unmap()) {
+				return ErrBlockedAddress
+			}
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // A proxy would dial the addresses on our behalf
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   options.Timeout,
+		ResponseHeaderTimeout: options.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &HTTPFetcher{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   options.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) > options.MaxRedirects {
+					return errors.New("too many redirects")
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return fmt.Errorf("unsupported redirect scheme: %s", req.URL.Scheme)
+				}
+				return nil
+			},
+		},
+		maxBytes: options.MaxBytes,
+	}
+}
+
+// Fetch downloads the page and returns its preview, pages without a title or a description
+// have no preview and return nil
+func (f *HTTPFetcher) Fetch(ctx context.Context, link string) (*models.LinkPreview, error) {
+	pageURL, err := url.Parse(link)
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") || pageURL.Host == "" {
+		return nil, fmt.Errorf("invalid link: %s", link)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("User-Agent", "GooglePlusLinkPreview/1.0")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch link: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("link returned status %d", resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, ErrNotHTML
+	}
+
+	// The metadata lives in the head, a truncated page is enough
+	preview, err := parseMetadata(io.LimitReader(resp.Body, f.maxBytes), resp.Request.URL)
+	if err != nil {
+		return nil, err
+	}
+	if preview.Title == "" && preview.Description == "" {
+		return nil, nil
+	}
+	preview.URL = link
+	return preview, nil
+}
+
+// reservedPrefixes are the special purpose ranges not covered by the netip helpers
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "This" network
+	netip.MustParsePrefix("100.64.0.0/10"),   // Carrier grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // Documentation
+	netip.MustParsePrefix("198.18.0.0/15"),   // Benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // Documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // Documentation
+	netip.MustParsePrefix("240.0.0.0/4"),     // Reserved, broadcast included
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, can embed private IPv4 addresses
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // Local use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),   // Documentation
+}
+
+// IsPublicAddress reports whether the address is a public unicast address
+func IsPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
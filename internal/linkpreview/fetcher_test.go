@@ -0,0 +1,214 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+)
+
+// standInAddress is the only address the test fetchers may dial
+var standInAddress = netip.MustParseAddr("127.0.0.1")
+
+func newTestFetcher(options Options) *HTTPFetcher {
+	options.AllowAddress = func(addr netip.Addr) bool { return addr == standInAddress }
+	return NewHTTPFetcher(options)
+}
+
+func newPageServer(t *testing.T, contentType string, page string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		fmt.Fprint(w, page)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestIsPublicAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},       // Loopback
+		{"::1", false},             // Loopback
+		{"10.1.2.3", false},        // RFC 1918
+		{"172.16.0.1", false},      // RFC 1918
+		{"192.168.1.1", false},     // RFC 1918
+		{"169.254.169.254", false}, // Link-local, cloud metadata
+		{"fe80::1", false},         // Link-local
+		{"fd00::1", false},         // Unique local
+		{"100.64.0.1", false},      // Carrier grade NAT
+		{"0.0.0.0", false},         // Unspecified
+		{"224.0.0.1", false},       // Multicast
+		{"64:ff9b::a00:1", false},  // NAT64 of 10.0.0.1
+		{"64:ff9b:1::1", false},    // Local use NAT64
+		{"::ffff:10.0.0.1", false}, // IPv4 mapped private address
+	}
+	for _, tt := range tests {
+		if got := IsPublicAddress(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("IsPublicAddress(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestFetchBlocksLoopback(t *testing.T) {
+	server := newPageServer(t, "text/html", "<title>Internal</title>")
+
+	// The default fetcher only dials public addresses
+	_, err := NewHTTPFetcher(Options{}).Fetch(context.Background(), server.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Fatalf("Fetch(%s) error = %v, want ErrBlockedAddress", server.URL, err)
+	}
+}
+
+func TestFetchBlocksRedirectTargets(t *testing.T) {
+	targets := []string{
+		"http://127.0.0.2/",        // Loopback
+		"http://10.0.0.1/",         // RFC 1918
+		"http://192.168.0.10/",     // RFC 1918
+		"http://169.254.169.254/",  // Link-local, cloud metadata
+		"http://[64:ff9b::a00:1]/", // NAT64 of 10.0.0.1
+	}
+
+	for _, target := range targets {
+		t.Run(target, func(t *testing.T) {
+			server := httptest.NewServer(http.RedirectHandler(target, http.StatusFound))
+			t.Cleanup(server.Close)
+
+			_, err := newTestFetcher(Options{Timeout: time.Second}).Fetch(context.Background(), server.URL)
+			if !errors.Is(err, ErrBlockedAddress) {
+				t.Fatalf("redirect to %s: error = %v, want ErrBlockedAddress", target, err)
+			}
+		})
+	}
+}
+
+func TestFetchRejectsNonHTML(t *testing.T) {
+	server := newPageServer(t, "application/json", `{"title": "Not a page"}`)
+
+	_, err := newTestFetcher(Options{}).Fetch(context.Background(), server.URL)
+	if !errors.Is(err, ErrNotHTML) {
+		t.Fatalf("error = %v, want ErrNotHTML", err)
+	}
+}
+
+func TestFetchTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	start := time.Now()
+	_, err := newTestFetcher(Options{Timeout: 100 * time.Millisecond}).Fetch(context.Background(), server.URL)
+	if err == nil {
+		t.Fatal("Fetch of a hanging page succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Fetch returned after %s, want the 100ms timeout", elapsed)
+	}
+}
+
+func TestFetchMaxBytes(t *testing.T) {
+	// The description comes after the cut-off and is never read
+	page := `<html><head><meta property="og:title" content="Early">` +
+		"<!--" + strings.Repeat("x", 2048) + "-->" +
+		`<meta property="og:description" content="Late"></head></html>`
+	server := newPageServer(t, "text/html; charset=utf-8", page)
+
+	preview, err := newTestFetcher(Options{MaxBytes: 1024}).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if preview == nil || preview.Title != "Early" || preview.Description != "" {
+		t.Fatalf("preview = %+v, want the title only", preview)
+	}
+}
+
+func TestFetchMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		head string
+		want models.LinkPreview
+	}{
+		{
+			name: "OpenGraph wins over the Twitter card and the title",
+			head: `<title>Page title</title>
+				<meta name="twitter:title" content="Twitter title">
+				<meta property="og:title" content="OG title">
+				<meta property="og:description" content="OG description">
+				<meta property="og:site_name" content="Example">
+				<meta property="og:image" content="/images/cover.png">`,
+			want: models.LinkPreview{Title: "OG title", Description: "OG description", SiteName: "Example", ImageURL: "/images/cover.png"},
+		},
+		{
+			name: "Twitter card without OpenGraph",
+			head: `<title>Page title</title>
+				<meta name="twitter:title" content="Twitter title">
+				<meta name="twitter:description" content="Twitter description">
+				<meta name="twitter:image" content="https://cdn.example.com/card.jpg">`,
+			want: models.LinkPreview{Title: "Twitter title", Description: "Twitter description", ImageURL: "https://cdn.example.com/card.jpg"},
+		},
+		{
+			name: "title and description fallback",
+			head: `<title>  Page
+				title &amp; more </title>
+				<meta name="description" content="Plain description">`,
+			want: models.LinkPreview{Title: "Page title & more", Description: "Plain description"},
+		},
+		{
+			name: "unsafe image scheme",
+			head: `<meta property="og:title" content="OG title">
+				<meta property="og:image" content="javascript:alert(1)">`,
+			want: models.LinkPreview{Title: "OG title"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newPageServer(t, "text/html", "<html><head>"+tt.head+"</head><body></body></html>")
+			link := server.URL + "/article"
+
+			preview, err := newTestFetcher(Options{}).Fetch(context.Background(), link)
+			if err != nil {
+				t.Fatalf("Fetch: %v", err)
+			}
+			if preview == nil {
+				t.Fatal("Fetch returned no preview")
+			}
+
+			want := tt.want
+			want.URL = link
+			if want.SiteName == "" {
+				want.SiteName = "127.0.0.1"
+			}
+			if strings.HasPrefix(want.ImageURL, "/") {
+				want.ImageURL = server.URL + want.ImageURL
+			}
+			if *preview != want {
+				t.Errorf("preview = %+v, want %+v", *preview, want)
+			}
+		})
+	}
+}
+
+func TestFetchWithoutMetadata(t *testing.T) {
+	server := newPageServer(t, "text/html", "<html><head></head><body><p>Hello</p></body></html>")
+
+	preview, err := newTestFetcher(Options{}).Fetch(context.Background(), server.URL)
+	if err != nil || preview != nil {
+		t.Fatalf("Fetch = %+v, %v, want no preview", preview, err)
+	}
+}
@@ -0,0 +1,136 @@
+package linkpreview
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"golang.org/x/net/html"
+)
+
+// Longest values kept in a preview, in characters
+const (
+	maxTitleLength       = 300
+	maxDescriptionLength = 1000
+	maxSiteNameLength    = 100
+	maxImageURLLength    = 2048
+)
+
+// parseMetadata reads the OpenGraph and Twitter card tags of a page, the <title> and the
+// description meta tag are used when the page has no card
+func parseMetadata(body io.Reader, pageURL *url.URL) (*models.LinkPreview, error) {
+	meta := make(map[string]string)
+	var title string
+
+	tokenizer := html.NewTokenizer(body)
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			// The end of the (possibly truncated) page
+			if err := tokenizer.Err(); err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			return buildPreview(meta, title, pageURL), nil
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "meta":
+				key, content := metaTag(token)
+				if key != "" && content != "" {
+					if _, seen := meta[key]; !seen {
+						meta[key] = content
+					}
+				}
+			case "title":
+				if title == "" && tokenizer.Next() == html.TextToken {
+					title = string(tokenizer.Text())
+				}
+			case "body":
+				// The metadata is in the head
+				return buildPreview(meta, title, pageURL), nil
+			}
+
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); string(name) == "head" {
+				return buildPreview(meta, title, pageURL), nil
+			}
+		}
+	}
+}
+
+// metaTag returns the lowercased property or name of a meta tag with its content
+func metaTag(token html.Token) (string, string) {
+	var key, content string
+	for _, attr := range token.Attr {
+		switch attr.Key {
+		case "property", "name":
+			if key == "" {
+				key = strings.ToLower(strings.TrimSpace(attr.Val))
+			}
+		case "content":
+			content = attr.Val
+		}
+	}
+	return key, content
+}
+
+func buildPreview(meta map[string]string, title string, pageURL *url.URL) *models.LinkPreview {
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if value := cleanText(meta[key]); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	preview := &models.LinkPreview{
+		Title:       first("og:title", "twitter:title"),
+		Description: first("og:description", "twitter:description", "description"),
+		SiteName:    first("og:site_name", "application-name"),
+		ImageURL:    resolveImageURL(first("og:image:secure_url", "og:image", "og:image:url", "twitter:image", "twitter:image:src"), pageURL),
+	}
+	if preview.Title == "" {
+		preview.Title = cleanText(title)
+	}
+	if preview.SiteName == "" {
+		preview.SiteName = strings.TrimPrefix(pageURL.Hostname(), "www.")
+	}
+
+	preview.Title = truncate(preview.Title, maxTitleLength)
+	preview.Description = truncate(preview.Description, maxDescriptionLength)
+	preview.SiteName = truncate(preview.SiteName, maxSiteNameLength)
+	return preview
+}
+
+// resolveImageURL makes relative image URLs absolute, only http and https images are kept
+func resolveImageURL(imageURL string, pageURL *url.URL) string {
+	if imageURL == "" {
+		return ""
+	}
+	parsed, err := pageURL.Parse(imageURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ""
+	}
+	if resolved := parsed.String(); len(resolved) <= maxImageURLLength {
+		return resolved
+	}
+	return ""
+}
+
+// cleanText collapses the whitespace of a value, entities are already decoded by the tokenizer
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:limit-1])) + "…"
+}
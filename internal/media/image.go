@@ -0,0 +1,143 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // Register the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Register the WebP decoder
+)
+
+// MaxImageBytes is the largest image upload accepted
+const MaxImageBytes = 10 << 20
+
+// maxImagePixels protects the decoder against small files that expand to huge images
+const maxImagePixels = 50_000_000
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image format, allowed formats are JPEG, PNG, GIF and WebP")
+	ErrImageTooLarge    = fmt.Errorf("image is too large, the limit is %d MB", MaxImageBytes>>20)
+)
+
+// allowedImageTypes are the sniffed content types accepted for uploads
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// imageVariantSizes is the longest side of each generated variant, images are never upscaled
+var imageVariantSizes = []struct {
+	Name    string
+	MaxSide int
+}{
+	{models.ImageVariantThumbnail, 320},
+	{models.ImageVariantFeed, 1080},
+	{models.ImageVariantFull, 2048},
+}
+
+// EncodedImage is a processed image variant ready to be stored
+type EncodedImage struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// ProcessImage validates an uploaded image and re-encodes it into the resized variants. The type
+// is sniffed from the content, never taken from the client. Re-encoding drops the EXIF data,
+// GPS position included, so the EXIF orientation is applied to the pixels first. Animated GIFs
+// keep their first frame.
+func ProcessImage(data []byte) ([]EncodedImage, error) {
+	if len(data) > MaxImageBytes {
+		return nil, ErrImageTooLarge
+	}
+	if !allowedImageTypes[http.DetectContentType(data)] {
+		return nil, ErrUnsupportedImage
+	}
+
+	// Check the dimensions before decoding the pixels
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	img = applyOrientation(img, jpegOrientation(data))
+
+	// Transparent images stay PNG, everything else becomes JPEG
+	opaque := true
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		opaque = o.Opaque()
+	}
+
+	variants := make([]EncodedImage, 0, len(imageVariantSizes))
+	for _, size := range imageVariantSizes {
+		resized := resizeToFit(img, size.MaxSide)
+
+		variant := EncodedImage{
+			Name:   size.Name,
+			Width:  resized.Bounds().Dx(),
+			Height: resized.Bounds().Dy(),
+		}
+
+		var buf bytes.Buffer
+		if opaque {
+			err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: 85})
+			variant.ContentType, variant.Extension = "image/jpeg", ".jpg"
+		} else {
+			err = png.Encode(&buf, resized)
+			variant.ContentType, variant.Extension = "image/png", ".png"
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s image: %w", size.Name, err)
+		}
+		variant.Data = buf.Bytes()
+
+		variants = append(variants, variant)
+	}
+
+	return variants, nil
+}
+
+// resizeToFit scales the image down so its longest side is at most maxSide
+func resizeToFit(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width > maxSide || height > maxSide {
+		if width >= height {
+			height = max(1, height*maxSide/width)
+			width = maxSide
+		} else {
+			width = max(1, width*maxSide/height)
+			height = maxSide
+		}
+	}
+
+	// Always draw into a new image so nothing from the decoded file is carried over
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if width == bounds.Dx() && height == bounds.Dy() {
+		draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+	} else {
+		xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, xdraw.Src, nil)
+	}
+	return dst
+}
@@ -0,0 +1,185 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+)
+
+func testPNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader builds the signature and the header chunk of a PNG claiming the given dimensions,
+// enough for the decoder to read the dimensions without any pixel data
+func pngHeader(width, height uint32) []byte {
+	ihdr := []byte("IHDR")
+	ihdr = binary.BigEndian.AppendUint32(ihdr, width)
+	ihdr = binary.BigEndian.AppendUint32(ihdr, height)
+	ihdr = append(ihdr, 8, 2, 0, 0, 0) // 8 bit RGB
+
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = binary.BigEndian.AppendUint32(data, uint32(len(ihdr)-4))
+	data = append(data, ihdr...)
+	return binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestProcessImageTypes(t *testing.T) {
+	opaque := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	transparent := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for i := range opaque.Pix {
+		opaque.Pix[i] = 0xFF
+	}
+
+	var gifData bytes.Buffer
+	if err := gif.Encode(&gifData, opaque, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		data            []byte
+		wantErr         error
+		wantContentType string
+	}{
+		{"JPEG", testJPEG(t, opaque), nil, "image/jpeg"},
+		{"opaque PNG", testPNG(t, opaque), nil, "image/jpeg"},
+		{"transparent PNG", testPNG(t, transparent), nil, "image/png"},
+		{"GIF", gifData.Bytes(), nil, "image/jpeg"},
+		{"BMP", append([]byte("BM"), make([]byte, 64)...), ErrUnsupportedImage, ""},
+		{"SVG", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), ErrUnsupportedImage, ""},
+		{"text", []byte("hello"), ErrUnsupportedImage, ""},
+		{"truncated JPEG", testJPEG(t, opaque)[:200], ErrUnsupportedImage, ""},
+		{"too many bytes", append(testJPEG(t, opaque), make([]byte, MaxImageBytes)...), ErrImageTooLarge, ""},
+		{"decompression bomb", pngHeader(10_000, 10_000), ErrImageTooLarge, ""},
+		{"too wide", pngHeader(maxImagePixels+1, 1), ErrImageTooLarge, ""},
+		{"zero width", pngHeader(0, 10), ErrUnsupportedImage, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variants, err := ProcessImage(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for _, variant := range variants {
+				if variant.ContentType != tt.wantContentType {
+					t.Errorf("%s content type = %s, want %s", variant.Name, variant.ContentType, tt.wantContentType)
+				}
+				if _, format, err := image.DecodeConfig(bytes.NewReader(variant.Data)); err != nil || "image/"+format != variant.ContentType {
+					t.Errorf("%s data is %s (%v), want %s", variant.Name, format, err, variant.ContentType)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessImageVariantSizes(t *testing.T) {
+	type size struct{ width, height int }
+
+	tests := []struct {
+		name   string
+		width  int
+		height int
+		want   map[string]size
+	}{
+		{"landscape", 3000, 1500, map[string]size{
+			models.ImageVariantThumbnail: {320, 160},
+			models.ImageVariantFeed:      {1080, 540},
+			models.ImageVariantFull:      {2048, 1024},
+		}},
+		{"portrait", 1000, 3000, map[string]size{
+			models.ImageVariantThumbnail: {106, 320},
+			models.ImageVariantFeed:      {360, 1080},
+			models.ImageVariantFull:      {682, 2048},
+		}},
+		{"small images are not upscaled", 200, 100, map[string]size{
+			models.ImageVariantThumbnail: {200, 100},
+			models.ImageVariantFeed:      {200, 100},
+			models.ImageVariantFull:      {200, 100},
+		}},
+		{"thin strip keeps one pixel", 4000, 2, map[string]size{
+			models.ImageVariantThumbnail: {320, 1},
+			models.ImageVariantFeed:      {1080, 1},
+			models.ImageVariantFull:      {2048, 1},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variants, err := ProcessImage(testPNG(t, image.NewGray(image.Rect(0, 0, tt.width, tt.height))))
+			if err != nil {
+				t.Fatalf("ProcessImage: %v", err)
+			}
+			if len(variants) != len(tt.want) {
+				t.Fatalf("got %d variants, want %d", len(variants), len(tt.want))
+			}
+			for _, variant := range variants {
+				want, ok := tt.want[variant.Name]
+				if !ok {
+					t.Errorf("unexpected variant %s", variant.Name)
+					continue
+				}
+				if variant.Width != want.width || variant.Height != want.height {
+					t.Errorf("%s = %dx%d, want %dx%d", variant.Name, variant.Width, variant.Height, want.width, want.height)
+				}
+				config, _, err := image.DecodeConfig(bytes.NewReader(variant.Data))
+				if err != nil || config.Width != variant.Width || config.Height != variant.Height {
+					t.Errorf("%s data is %dx%d (%v), want %dx%d", variant.Name, config.Width, config.Height, err, variant.Width, variant.Height)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessImageOrientation(t *testing.T) {
+	// The left half is black and the right half white, turned upright by orientation 6 the black
+	// half is on top
+	img := image.NewGray(image.Rect(0, 0, 64, 32))
+	for y := 0; y < 32; y++ {
+		for x := 32; x < 64; x++ {
+			img.SetGray(x, y, color.Gray{Y: 0xFF})
+		}
+	}
+	data := withSegment(testJPEG(t, img), exifSegment(binary.BigEndian, 6))
+
+	variants, err := ProcessImage(data)
+	if err != nil {
+		t.Fatalf("ProcessImage: %v", err)
+	}
+	for _, variant := range variants {
+		if variant.Width != 32 || variant.Height != 64 {
+			t.Errorf("%s = %dx%d, want 32x64", variant.Name, variant.Width, variant.Height)
+		}
+		if bytes.Contains(variant.Data, []byte("Exif\x00\x00")) {
+			t.Errorf("%s still holds the EXIF data", variant.Name)
+		}
+
+		decoded, err := jpeg.Decode(bytes.NewReader(variant.Data))
+		if err != nil {
+			t.Fatalf("%s: %v", variant.Name, err)
+		}
+		top := color.GrayModel.Convert(decoded.At(16, 8)).(color.Gray).Y
+		bottom := color.GrayModel.Convert(decoded.At(16, 56)).(color.Gray).Y
+		if top > 0x40 || bottom < 0xC0 {
+			t.Errorf("%s top = %d, bottom = %d, want a black top and a white bottom", variant.Name, top, bottom)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalURLPrefix is the route the local store files are served from
+const LocalURLPrefix = "/uploads"
+
+// LocalStore keeps media on the local filesystem, it only works with a single server instance
+type LocalStore struct {
+	Dir       string // Directory the files are written to
+	PublicURL string // Optional base URL of the files, served by the app under LocalURLPrefix otherwise
+}
+
+// NewLocalStore creates a store writing to dir
+func NewLocalStore(dir string, publicURL string) *LocalStore {
+	return &LocalStore{Dir: dir, PublicURL: strings.TrimSuffix(publicURL, "/")}
+}
+
+func (s *LocalStore) Save(ctx context.Context, key string, content io.Reader, size int64, contentType string) (string, error) {
+	key = strings.TrimPrefix(path.Clean("/"+key), "/")
+	filePath, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	// Ensure the directory structure exists
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Create a destination file
+	dst, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer dst.Close()
+
+	// Copy the file content to the destination
+	if _, err := io.Copy(dst, content); err != nil {
+		return "", fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	if s.PublicURL != "" {
+		return s.PublicURL + "/" + key, nil
+	}
+	return path.Join(LocalURLPrefix, key), nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) Key(mediaURL string) (string, bool) {
+	if s.PublicURL != "" {
+		return localKey(mediaURL, s.PublicURL+"/")
+	}
+
+	// Server relative URLs are usually resolved against the request base URL before being saved
+	u, err := url.Parse(mediaURL)
+	if err != nil {
+		return "", false
+	}
+	return localKey(u.Path, LocalURLPrefix+"/")
+}
+
+// localKey strips the URL prefix of the store from a URL
+func localKey(mediaURL string, prefix string) (string, bool) {
+	key, ok := strings.CutPrefix(mediaURL, prefix)
+	if !ok || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+// path maps a key to a file inside the store directory
+func (s *LocalStore) path(key string) (string, error) {
+	cleanKey := path.Clean("/" + key)
+	if cleanKey == "/" {
+		return "", fmt.Errorf("invalid media key: %q", key)
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(cleanKey)), nil
+}
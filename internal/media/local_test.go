@@ -0,0 +1,103 @@
+package media
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStoreSave(t *testing.T) {
+	tests := []struct {
+		name      string
+		publicURL string
+		key       string
+		wantFile  string
+		wantURL   string
+	}{
+		{"relative URL", "", "images/1/feed.jpg", "images/1/feed.jpg", "/uploads/images/1/feed.jpg"},
+		{"public URL", "https://cdn.example.com/", "images/1/feed.jpg", "images/1/feed.jpg", "https://cdn.example.com/images/1/feed.jpg"},
+		{"leading slash", "", "/videos/clip.mp4", "videos/clip.mp4", "/uploads/videos/clip.mp4"},
+		{"parent directories", "", "../../etc/passwd", "etc/passwd", "/uploads/etc/passwd"},
+		{"dot segments", "", "images/./1/../2/full.png", "images/2/full.png", "/uploads/images/2/full.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store := NewLocalStore(dir, tt.publicURL)
+
+			mediaURL, err := store.Save(context.Background(), tt.key, strings.NewReader("content"), 7, "image/jpeg")
+			if err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			if mediaURL != tt.wantURL {
+				t.Errorf("URL = %s, want %s", mediaURL, tt.wantURL)
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(tt.wantFile)))
+			if err != nil {
+				t.Fatalf("stored file: %v", err)
+			}
+			if string(data) != "content" {
+				t.Errorf("stored content = %q, want %q", data, "content")
+			}
+
+			key, ok := store.Key(mediaURL)
+			if !ok || key != tt.wantFile {
+				t.Errorf("Key(%s) = %q, %v, want %q", mediaURL, key, ok, tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestLocalStoreSaveRejectsEmptyKey(t *testing.T) {
+	store := NewLocalStore(t.TempDir(), "")
+	for _, key := range []string{"", "/", "..", "../.."} {
+		if _, err := store.Save(context.Background(), key, strings.NewReader("content"), 7, "image/jpeg"); err == nil {
+			t.Errorf("Save(%q) succeeded, want an error", key)
+		}
+	}
+}
+
+func TestLocalStoreDelete(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocalStore(dir, "")
+
+	if _, err := store.Save(context.Background(), "images/1/feed.jpg", strings.NewReader("content"), 7, "image/jpeg"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Delete(context.Background(), "../images/1/feed.jpg"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "images", "1", "feed.jpg")); !os.IsNotExist(err) {
+		t.Errorf("file still exists after Delete: %v", err)
+	}
+
+	// Deleting a missing file is not an error
+	if err := store.Delete(context.Background(), "images/1/feed.jpg"); err != nil {
+		t.Errorf("Delete of a missing file: %v", err)
+	}
+}
+
+func TestLocalStoreKey(t *testing.T) {
+	store := NewLocalStore(t.TempDir(), "")
+
+	tests := []struct {
+		url     string
+		wantKey string
+		wantOK  bool
+	}{
+		{"/uploads/images/1/feed.jpg", "images/1/feed.jpg", true},
+		{"http://localhost:3000/uploads/images/1/feed.jpg", "images/1/feed.jpg", true},
+		{"http://localhost:3000/uploads/", "", false},
+		{"https://cdn.example.com/images/1/feed.jpg", "", false},
+	}
+	for _, tt := range tests {
+		key, ok := store.Key(tt.url)
+		if key != tt.wantKey || ok != tt.wantOK {
+			t.Errorf("Key(%s) = %q, %v, want %q, %v", tt.url, key, ok, tt.wantKey, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// exifSegment builds an APP1 segment whose first IFD holds the orientation tag
+func exifSegment(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 26)
+	copy(tiff, "MM")
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)       // Offset of the first IFD
+	order.PutUint16(tiff[8:], 1)       // One entry
+	order.PutUint16(tiff[10:], 0x0112) // Orientation
+	order.PutUint16(tiff[12:], 3)      // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := binary.BigEndian.AppendUint16([]byte{0xFF, 0xE1}, uint16(2+len(payload)))
+	return append(segment, payload...)
+}
+
+// withSegment inserts a segment right after the start of image marker of a JPEG file
+func withSegment(jpegData, segment []byte) []byte {
+	return bytes.Join([][]byte{jpegData[:2], segment, jpegData[2:]}, nil)
+}
+
+func testJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	plain := testJPEG(t, image.NewGray(image.Rect(0, 0, 8, 8)))
+
+	type test struct {
+		name string
+		data []byte
+		want int
+	}
+	var tests []test
+	for orientation := 1; orientation <= 8; orientation++ {
+		tests = append(tests,
+			test{fmt.Sprintf("big endian %d", orientation), withSegment(plain, exifSegment(binary.BigEndian, uint16(orientation))), orientation},
+			test{fmt.Sprintf("little endian %d", orientation), withSegment(plain, exifSegment(binary.LittleEndian, uint16(orientation))), orientation},
+		)
+	}
+
+	outOfRange := exifSegment(binary.BigEndian, 9)
+	badByteOrder := exifSegment(binary.BigEndian, 6)
+	copy(badByteOrder[10:], "XX")
+	ifdPastTheEnd := exifSegment(binary.BigEndian, 6)
+	binary.BigEndian.PutUint32(ifdPastTheEnd[14:], 4096)
+	tooLong := exifSegment(binary.BigEndian, 6)
+	binary.BigEndian.PutUint16(tooLong[2:], 0xFFFF)
+	afterImageData := append(bytes.Clone(plain[:len(plain)-2]), exifSegment(binary.BigEndian, 6)...)
+
+	tests = append(tests,
+		test{"no EXIF", plain, 1},
+		test{"not a JPEG", []byte("\x89PNG\r\n\x1a\n"), 1},
+		test{"empty", nil, 1},
+		test{"orientation out of range", withSegment(plain, outOfRange), 1},
+		test{"unknown byte order", withSegment(plain, badByteOrder), 1},
+		test{"IFD past the end", withSegment(plain, ifdPastTheEnd), 1},
+		test{"segment longer than the file", withSegment(plain[:2], tooLong), 1},
+		test{"EXIF after the image data", afterImageData, 1},
+	)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("jpegOrientation = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// The stored image is 2 pixels wide and 3 high, each pixel is labelled with its gray level
+	src := image.NewGray(image.Rect(0, 0, 2, 3))
+	copy(src.Pix, []uint8{1, 2, 3, 4, 5, 6})
+
+	tests := []struct {
+		orientation int
+		want        [][]uint8 // Rows of the upright image
+	}{
+		{1, [][]uint8{{1, 2}, {3, 4}, {5, 6}}},
+		{2, [][]uint8{{2, 1}, {4, 3}, {6, 5}}},
+		{3, [][]uint8{{6, 5}, {4, 3}, {2, 1}}},
+		{4, [][]uint8{{5, 6}, {3, 4}, {1, 2}}},
+		{5, [][]uint8{{1, 3, 5}, {2, 4, 6}}},
+		{6, [][]uint8{{5, 3, 1}, {6, 4, 2}}},
+		{7, [][]uint8{{6, 4, 2}, {5, 3, 1}}},
+		{8, [][]uint8{{2, 4, 6}, {1, 3, 5}}},
+		{0, [][]uint8{{1, 2}, {3, 4}, {5, 6}}},
+		{9, [][]uint8{{1, 2}, {3, 4}, {5, 6}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.orientation), func(t *testing.T) {
+			got := applyOrientation(src, tt.orientation)
+
+			bounds := got.Bounds()
+			if bounds.Dx() != len(tt.want[0]) || bounds.Dy() != len(tt.want) {
+				t.Fatalf("size = %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), len(tt.want[0]), len(tt.want))
+			}
+			for y, row := range tt.want {
+				for x, want := range row {
+					pixel := color.GrayModel.Convert(got.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray)
+					if pixel.Y != want {
+						t.Errorf("pixel (%d, %d) = %d, want %d", x, y, pixel.Y, want)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config holds the settings of an S3 compatible object storage such as AWS S3 or MinIO
+type S3Config struct {
+	Endpoint     string // e.g. https://s3.eu-central-1.amazonaws.com or http://localhost:9000
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	PublicURL    string // Optional base URL of the objects, e.g. a CDN in front of the bucket
+	UsePathStyle bool   // Address the bucket as endpoint/bucket instead of bucket.endpoint, needed by MinIO
+}
+
+// S3Store keeps media in an S3 compatible bucket. Requests are signed with AWS Signature
+// Version 4, the bucket must allow public reads for the returned URLs to work.
+type S3Store struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Store creates a store for the configured bucket
+func NewS3Store(config S3Config) (*S3Store, error) {
+	if config.Endpoint == "" || config.Bucket == "" || config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY and S3_SECRET_KEY are required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3_ENDPOINT: %s", config.Endpoint)
+	}
+	config.PublicURL = strings.TrimSuffix(config.PublicURL, "/")
+
+	return &S3Store{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 60 * time.Second},
+		now:      time.Now,
+	}, nil
+}
+
+func (s *S3Store) Save(ctx context.Context, key string, content io.Reader, size int64, contentType string) (string, error) {
+	// The payload hash is part of the signature so the content is read up front
+	body, err := io.ReadAll(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to read media content: %w", err)
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	if err := s.do(ctx, http.MethodPut, key, header, body); err != nil {
+		return "", err
+	}
+
+	return s.objectURL(key), nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	return s.do(ctx, http.MethodDelete, key, http.Header{}, nil)
+}
+
+func (s *S3Store) Key(mediaURL string) (string, bool) {
+	base := s.requestURL("").String()
+	if s.config.PublicURL != "" {
+		base = s.config.PublicURL + "/"
+	}
+	escapedKey, ok := strings.CutPrefix(mediaURL, base)
+	if !ok || escapedKey == "" {
+		return "", false
+	}
+	key, err := url.PathUnescape(escapedKey)
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// do sends a signed request for an object and checks the response status
+func (s *S3Store) do(ctx context.Context, method string, key string, header http.Header, body []byte) error {
+	requestURL := s.requestURL(key)
+	req, err := http.NewRequestWithContext(ctx, method, requestURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build S3 request: %w", err)
+	}
+	req.Header = header
+	req.ContentLength = int64(len(body))
+	s.sign(req, body)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("S3 request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("S3 %s %s returned %d: %s", method, key, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// requestURL addresses an object in path style or virtual hosted style
+func (s *S3Store) requestURL(key string) *url.URL {
+	u := *s.endpoint
+	objectPath := "/" + strings.TrimPrefix(key, "/")
+	if s.config.UsePathStyle {
+		objectPath = "/" + s.config.Bucket + objectPath
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+	}
+	basePath := strings.TrimSuffix(u.Path, "/")
+	u.Path = basePath + objectPath
+	u.RawPath = basePath + escapePath(objectPath)
+	return &u
+}
+
+// objectURL is the public URL of an object
+func (s *S3Store) objectURL(key string) string {
+	escapedKey := escapePath(strings.TrimPrefix(key, "/"))
+	if s.config.PublicURL != "" {
+		return s.config.PublicURL + "/" + escapedKey
+	}
+	return s.requestURL(key).String()
+}
+
+// sign adds the AWS Signature Version 4 headers to the request
+func (s *S3Store) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Every header set so far is signed
+	var signedHeaders []string
+	canonicalHeaders := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		canonicalHeaders[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	for name := range canonicalHeaders {
+		signedHeaders = append(signedHeaders, name)
+	}
+	sort.Strings(signedHeaders)
+
+	var headerLines strings.Builder
+	for _, name := range signedHeaders {
+		headerLines.WriteString(name + ":" + canonicalHeaders[name] + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		headerLines.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.config.SecretKey), date)
+	signingKey = hmacSHA256(signingKey, s.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKey, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+// escapePath encodes every byte of a path except the unreserved characters and "/"
+func escapePath(p string) string {
+	var escaped strings.Builder
+	for _, b := range []byte(p) {
+		if b == '/' || b == '-' || b == '_' || b == '.' || b == '~' ||
+			('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9') {
+			escaped.WriteByte(b)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
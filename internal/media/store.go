@@ -0,0 +1,61 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// MediaStore saves uploaded media and returns the URL clients should load it from
+type MediaStore interface {
+	// Save stores the content under key and returns its public URL. A URL starting with "/"
+	// is relative to the server and must be resolved against the request base URL.
+	Save(ctx context.Context, key string, content io.Reader, size int64, contentType string) (string, error)
+	// Delete removes the content stored under key, missing keys are not an error
+	Delete(ctx context.Context, key string) error
+	// Key returns the key a URL returned by Save was stored under, false when the URL doesn't
+	// belong to this store
+	Key(mediaURL string) (string, bool)
+}
+
+// Store is the media backend selected at startup
+var Store MediaStore
+
+// Setup selects the media backend from the environment. MEDIA_STORE is "local" (default)
+// or "s3" for any S3 compatible object storage.
+func Setup() {
+	var err error
+	Store, err = newStoreFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to set up media storage: %v", err)
+	}
+}
+
+func newStoreFromEnv() (MediaStore, error) {
+	switch backend := strings.ToLower(os.Getenv("MEDIA_STORE")); backend {
+	case "", "local":
+		dir := os.Getenv("MEDIA_LOCAL_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocalStore(dir, os.Getenv("MEDIA_PUBLIC_URL")), nil
+
+	case "s3":
+		config := S3Config{
+			Endpoint:     os.Getenv("S3_ENDPOINT"),
+			Region:       os.Getenv("S3_REGION"),
+			Bucket:       os.Getenv("S3_BUCKET"),
+			AccessKey:    os.Getenv("S3_ACCESS_KEY"),
+			SecretKey:    os.Getenv("S3_SECRET_KEY"),
+			PublicURL:    os.Getenv("MEDIA_PUBLIC_URL"),
+			UsePathStyle: os.Getenv("S3_USE_PATH_STYLE") == "true",
+		}
+		return NewS3Store(config)
+
+	default:
+		return nil, fmt.Errorf("unknown MEDIA_STORE: %s", backend)
+	}
+}
@@ -0,0 +1,287 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// MaxVideoBytes is the largest video upload accepted
+const MaxVideoBytes = 50 << 20
+
+// MaxVideoDuration is the longest video accepted
+const MaxVideoDuration = 60 * time.Second
+
+// posterTimeout bounds the ffmpeg run extracting the poster frame
+const posterTimeout = 15 * time.Second
+
+var (
+	ErrUnsupportedVideo = errors.New("unsupported video, only MP4 or QuickTime files with H.264 or HEVC video and AAC audio are allowed")
+	ErrVideoTooLarge    = fmt.Errorf("video is too large, the limit is %d MB", MaxVideoBytes>>20)
+	ErrVideoTooLong     = fmt.Errorf("video is too long, the limit is %d seconds", int(MaxVideoDuration.Seconds()))
+	// ErrFFmpegUnavailable is returned by ExtractPosterFrame when no ffmpeg binary is installed
+	ErrFFmpegUnavailable = errors.New("ffmpeg is not available")
+)
+
+// allowedVideoCodecs and allowedAudioCodecs are the sample entry formats accepted in the tracks
+var (
+	allowedVideoCodecs = map[string]bool{"avc1": true, "avc3": true, "hvc1": true, "hev1": true}
+	allowedAudioCodecs = map[string]bool{"mp4a": true}
+)
+
+// VideoInfo describes a validated video
+type VideoInfo struct {
+	ContentType string
+	Extension   string
+	Duration    time.Duration
+	Width       int
+	Height      int
+	VideoCodec  string
+	AudioCodec  string
+}
+
+// IsVideo reports whether the first bytes of a file look like an MP4 or QuickTime container
+func IsVideo(header []byte) bool {
+	return len(header) >= 12 && string(header[4:8]) == "ftyp"
+}
+
+// InspectVideo validates an MP4 or QuickTime video by reading its boxes. The container, the
+// codecs, the size and the duration are checked, the content itself is not decoded.
+func InspectVideo(r io.ReaderAt, size int64) (*VideoInfo, error) {
+	if size > MaxVideoBytes {
+		return nil, ErrVideoTooLarge
+	}
+
+	header := make([]byte, 12)
+	if _, err := r.ReadAt(header, 0); err != nil || !IsVideo(header) {
+		return nil, ErrUnsupportedVideo
+	}
+
+	info := &VideoInfo{ContentType: "video/mp4", Extension: ".mp4"}
+	if string(header[8:12]) == "qt  " {
+		info.ContentType, info.Extension = "video/quicktime", ".mov"
+	}
+
+	parser := &mp4Parser{r: r, info: info}
+	if err := parser.walk(0, size, 0); err != nil {
+		return nil, err
+	}
+	if !parser.foundMovie || info.VideoCodec == "" {
+		return nil, ErrUnsupportedVideo
+	}
+	if !allowedVideoCodecs[info.VideoCodec] {
+		return nil, ErrUnsupportedVideo
+	}
+	if info.AudioCodec != "" && !allowedAudioCodecs[info.AudioCodec] {
+		return nil, ErrUnsupportedVideo
+	}
+	if info.Duration <= 0 {
+		return nil, ErrUnsupportedVideo
+	}
+	if info.Duration > MaxVideoDuration {
+		return nil, ErrVideoTooLong
+	}
+
+	return info, nil
+}
+
+// mp4Parser walks the boxes of an ISO base media file and collects what InspectVideo checks
+type mp4Parser struct {
+	r          io.ReaderAt
+	info       *VideoInfo
+	foundMovie bool
+	boxes      int
+
+	// Track being read
+	handler string
+	width   int
+	height  int
+}
+
+// mp4Containers are the boxes holding the boxes InspectVideo reads
+var mp4Containers = map[string]bool{"moov": true, "trak": true, "mdia": true, "minf": true, "stbl": true}
+
+const (
+	maxMP4Depth = 8
+	maxMP4Boxes = 10000
+)
+
+func (p *mp4Parser) walk(start int64, end int64, depth int) error {
+	if depth > maxMP4Depth {
+		return ErrUnsupportedVideo
+	}
+
+	for offset := start; offset+8 <= end; {
+		if p.boxes++; p.boxes > maxMP4Boxes {
+			return ErrUnsupportedVideo
+		}
+
+		header := make([]byte, 16)
+		if _, err := p.r.ReadAt(header[:8], offset); err != nil {
+			return ErrUnsupportedVideo
+		}
+		boxSize := int64(binary.BigEndian.Uint32(header[:4]))
+		boxType := string(header[4:8])
+		headerSize := int64(8)
+
+		switch boxSize {
+		case 0: // The box runs to the end of the file
+			boxSize = end - offset
+		case 1: // 64 bit size
+			if _, err := p.r.ReadAt(header[8:16], offset+8); err != nil {
+				return ErrUnsupportedVideo
+			}
+			boxSize = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+		if boxSize < headerSize || offset+boxSize > end {
+			return ErrUnsupportedVideo
+		}
+
+		payloadStart, payloadEnd := offset+headerSize, offset+boxSize
+		if err := p.readBox(boxType, payloadStart, payloadEnd, depth); err != nil {
+			return err
+		}
+		offset += boxSize
+	}
+	return nil
+}
+
+func (p *mp4Parser) readBox(boxType string, start int64, end int64, depth int) error {
+	if mp4Containers[boxType] {
+		if boxType == "moov" {
+			p.foundMovie = true
+		}
+		if boxType == "trak" {
+			p.handler, p.width, p.height = "", 0, 0
+		}
+		return p.walk(start, end, depth+1)
+	}
+
+	// The boxes below are small, anything else is skipped without being read
+	switch boxType {
+	case "mvhd", "tkhd", "hdlr", "stsd":
+	default:
+		return nil
+	}
+	if end-start > 1<<20 {
+		return ErrUnsupportedVideo
+	}
+	payload := make([]byte, end-start)
+	if _, err := p.r.ReadAt(payload, start); err != nil {
+		return ErrUnsupportedVideo
+	}
+
+	switch boxType {
+	case "mvhd":
+		// version(1) flags(3), then the times, the timescale and the duration
+		if len(payload) < 4 {
+			return ErrUnsupportedVideo
+		}
+		var timescale, duration uint64
+		if payload[0] == 1 {
+			if len(payload) < 32 {
+				return ErrUnsupportedVideo
+			}
+			timescale = uint64(binary.BigEndian.Uint32(payload[20:24]))
+			duration = binary.BigEndian.Uint64(payload[24:32])
+		} else {
+			if len(payload) < 20 {
+				return ErrUnsupportedVideo
+			}
+			timescale = uint64(binary.BigEndian.Uint32(payload[12:16]))
+			duration = uint64(binary.BigEndian.Uint32(payload[16:20]))
+		}
+		if timescale == 0 {
+			return ErrUnsupportedVideo
+		}
+		seconds := float64(duration) / float64(timescale)
+		if seconds > MaxVideoDuration.Seconds() {
+			return ErrVideoTooLong
+		}
+		p.info.Duration = time.Duration(seconds * float64(time.Second))
+
+	case "tkhd":
+		// The track ends with the transformation matrix and the 16.16 fixed point dimensions
+		if len(payload) < 44 {
+			return ErrUnsupportedVideo
+		}
+		matrix := payload[len(payload)-44:]
+		p.width = int(binary.BigEndian.Uint32(payload[len(payload)-8:]) >> 16)
+		p.height = int(binary.BigEndian.Uint32(payload[len(payload)-4:]) >> 16)
+		// A rotated track (portrait phone videos) swaps the displayed dimensions
+		if int32(binary.BigEndian.Uint32(matrix[0:4])) == 0 {
+			p.width, p.height = p.height, p.width
+		}
+
+	case "hdlr":
+		// version(1) flags(3) pre_defined(4) handler_type(4)
+		if len(payload) < 12 {
+			return ErrUnsupportedVideo
+		}
+		p.handler = string(payload[8:12])
+
+	case "stsd":
+		// version(1) flags(3) entry_count(4), then the first sample entry size(4) format(4)
+		if len(payload) < 16 {
+			return ErrUnsupportedVideo
+		}
+		codec := string(payload[12:16])
+		switch p.handler {
+		case "vide":
+			if p.info.VideoCodec != "" {
+				return ErrUnsupportedVideo // A single video track is expected
+			}
+			p.info.VideoCodec = codec
+			p.info.Width, p.info.Height = p.width, p.height
+		case "soun":
+			if p.info.AudioCodec == "" {
+				p.info.AudioCodec = codec
+			}
+		}
+	}
+	return nil
+}
+
+// ExtractPosterFrame grabs the first frame of a video with the local ffmpeg binary and returns it
+// as a PNG image. ErrFFmpegUnavailable is returned when ffmpeg isn't installed.
+func ExtractPosterFrame(video io.Reader) ([]byte, error) {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, ErrFFmpegUnavailable
+	}
+
+	// ffmpeg needs to seek in MP4 files whose index is at the end, so it reads a temporary copy
+	tmp, err := os.CreateTemp("", "video-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	if _, err := io.Copy(tmp, video); err != nil {
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), posterTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffmpeg, "-hide_banner", "-loglevel", "error",
+		"-i", tmp.Name(), "-frames:v", "1", "-f", "image2", "-c:v", "png", "pipe:1")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	if stdout.Len() == 0 {
+		return nil, errors.New("ffmpeg returned no frame")
+	}
+
+	return stdout.Bytes(), nil
+}
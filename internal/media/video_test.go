@@ -0,0 +1,172 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+// mp4Box builds a box with a 32 bit size around the payloads
+func mp4Box(boxType string, payloads ...[]byte) []byte {
+	payload := bytes.Join(payloads, nil)
+	box := binary.BigEndian.AppendUint32(nil, uint32(8+len(payload)))
+	box = append(box, boxType...)
+	return append(box, payload...)
+}
+
+// mp4LargeBox builds a box with a 64 bit size
+func mp4LargeBox(boxType string, payloads ...[]byte) []byte {
+	payload := bytes.Join(payloads, nil)
+	box := binary.BigEndian.AppendUint32(nil, 1)
+	box = append(box, boxType...)
+	box = binary.BigEndian.AppendUint64(box, uint64(16+len(payload)))
+	return append(box, payload...)
+}
+
+func ftypBox(brand string) []byte {
+	return mp4Box("ftyp", []byte(brand), make([]byte, 4), []byte("isom"))
+}
+
+func mvhdBox(timescale, duration uint32) []byte {
+	payload := make([]byte, 100)
+	binary.BigEndian.PutUint32(payload[12:], timescale)
+	binary.BigEndian.PutUint32(payload[16:], duration)
+	return mp4Box("mvhd", payload)
+}
+
+func mvhdBoxV1(timescale uint32, duration uint64) []byte {
+	payload := make([]byte, 112)
+	payload[0] = 1
+	binary.BigEndian.PutUint32(payload[20:], timescale)
+	binary.BigEndian.PutUint64(payload[24:], duration)
+	return mp4Box("mvhd", payload)
+}
+
+// tkhdBox builds a version 0 track header, a rotated track has the matrix of a 90 degree turn
+func tkhdBox(width, height int, rotated bool) []byte {
+	payload := make([]byte, 84)
+	matrix := payload[40:76]
+	if rotated {
+		binary.BigEndian.PutUint32(matrix[4:], 0x00010000)
+		binary.BigEndian.PutUint32(matrix[12:], 0xffff0000)
+	} else {
+		binary.BigEndian.PutUint32(matrix[0:], 0x00010000)
+		binary.BigEndian.PutUint32(matrix[16:], 0x00010000)
+	}
+	binary.BigEndian.PutUint32(matrix[32:], 0x40000000)
+	binary.BigEndian.PutUint32(payload[76:], uint32(width)<<16)
+	binary.BigEndian.PutUint32(payload[80:], uint32(height)<<16)
+	return mp4Box("tkhd", payload)
+}
+
+func trakBox(handler, codec string, tkhd []byte) []byte {
+	hdlr := make([]byte, 25)
+	copy(hdlr[8:], handler)
+
+	stsd := make([]byte, 24)
+	binary.BigEndian.PutUint32(stsd[4:], 1)
+	binary.BigEndian.PutUint32(stsd[8:], 16)
+	copy(stsd[12:], codec)
+
+	return mp4Box("trak", tkhd,
+		mp4Box("mdia", mp4Box("hdlr", hdlr),
+			mp4Box("minf", mp4Box("stbl", mp4Box("stsd", stsd)))))
+}
+
+func videoTrak(codec string) []byte { return trakBox("vide", codec, tkhdBox(1920, 1080, false)) }
+
+func audioTrak(codec string) []byte { return trakBox("soun", codec, tkhdBox(0, 0, false)) }
+
+// videoFile lays out a file with its media data before the movie box, the way cameras write them
+func videoFile(brand string, moov ...[]byte) []byte {
+	return bytes.Join([][]byte{ftypBox(brand), mp4Box("mdat", make([]byte, 64)), mp4Box("moov", moov...)}, nil)
+}
+
+func TestInspectVideo(t *testing.T) {
+	landscape := &VideoInfo{ContentType: "video/mp4", Extension: ".mp4", Duration: 30 * time.Second,
+		Width: 1920, Height: 1080, VideoCodec: "avc1", AudioCodec: "mp4a"}
+
+	// A trak box claiming 8 more bytes than its movie box holds
+	overflowingTrak := videoTrak("avc1")
+	binary.BigEndian.PutUint32(overflowingTrak, uint32(len(overflowingTrak)+8))
+
+	// A box with a 64 bit size far past the end of the file
+	hugeBox := append(binary.BigEndian.AppendUint32(nil, 1), "free"...)
+	hugeBox = binary.BigEndian.AppendUint64(hugeBox, 1<<40)
+
+	// A movie header that would be valid if it was not padded past the size read in memory
+	oversizedMvhd := append(mvhdBox(1000, 30000), make([]byte, 1<<20)...)
+	binary.BigEndian.PutUint32(oversizedMvhd, uint32(len(oversizedMvhd)))
+
+	valid := videoFile("isom", mvhdBox(1000, 30000), videoTrak("avc1"), audioTrak("mp4a"))
+
+	nested := mp4Box("trak", videoTrak("avc1"))
+	for i := 0; i < maxMP4Depth; i++ {
+		nested = mp4Box("trak", nested)
+	}
+
+	tests := []struct {
+		name    string
+		file    []byte
+		want    *VideoInfo
+		wantErr error
+	}{
+		{"valid", valid, landscape, nil},
+		{
+			name: "quicktime",
+			file: videoFile("qt  ", mvhdBox(600, 6000), videoTrak("hvc1")),
+			want: &VideoInfo{ContentType: "video/quicktime", Extension: ".mov", Duration: 10 * time.Second,
+				Width: 1920, Height: 1080, VideoCodec: "hvc1"},
+		},
+		{
+			name: "rotated track",
+			file: videoFile("isom", mvhdBox(1000, 30000), trakBox("vide", "avc1", tkhdBox(1920, 1080, true)), audioTrak("mp4a")),
+			want: &VideoInfo{ContentType: "video/mp4", Extension: ".mp4", Duration: 30 * time.Second,
+				Width: 1080, Height: 1920, VideoCodec: "avc1", AudioCodec: "mp4a"},
+		},
+		{"64 bit sizes", bytes.Join([][]byte{ftypBox("isom"), mp4LargeBox("mdat", make([]byte, 64)),
+			mp4LargeBox("moov", mvhdBox(1000, 30000), videoTrak("avc1"), audioTrak("mp4a"))}, nil), landscape, nil},
+		{"last box to the end of the file", append(bytes.Clone(valid), 0, 0, 0, 0, 'f', 'r', 'e', 'e', 1, 2, 3), landscape, nil},
+		{"version 1 movie header", videoFile("isom", mvhdBoxV1(1000, 30000), videoTrak("avc1"), audioTrak("mp4a")), landscape, nil},
+
+		{"too long", videoFile("isom", mvhdBox(1000, 61000), videoTrak("avc1")), nil, ErrVideoTooLong},
+		{"too long with a 64 bit duration", videoFile("isom", mvhdBoxV1(1000, 1<<40), videoTrak("avc1")), nil, ErrVideoTooLong},
+		{"no duration", videoFile("isom", mvhdBox(1000, 0), videoTrak("avc1")), nil, ErrUnsupportedVideo},
+		{"zero timescale", videoFile("isom", mvhdBox(0, 30000), videoTrak("avc1")), nil, ErrUnsupportedVideo},
+		{"disallowed video codec", videoFile("isom", mvhdBox(1000, 30000), videoTrak("vp09")), nil, ErrUnsupportedVideo},
+		{"disallowed audio codec", videoFile("isom", mvhdBox(1000, 30000), videoTrak("avc1"), audioTrak("Opus")), nil, ErrUnsupportedVideo},
+		{"second video track", videoFile("isom", mvhdBox(1000, 30000), videoTrak("avc1"), videoTrak("avc1")), nil, ErrUnsupportedVideo},
+		{"audio only", videoFile("isom", mvhdBox(1000, 30000), audioTrak("mp4a")), nil, ErrUnsupportedVideo},
+		{"no movie box", bytes.Join([][]byte{ftypBox("isom"), mp4Box("mdat", make([]byte, 64))}, nil), nil, ErrUnsupportedVideo},
+		{"not a video", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"), nil, ErrUnsupportedVideo},
+
+		{"truncated file", valid[:len(valid)-10], nil, ErrUnsupportedVideo},
+		{"box larger than its parent", videoFile("isom", mvhdBox(1000, 30000), overflowingTrak), nil, ErrUnsupportedVideo},
+		{"box smaller than its header", append(bytes.Clone(valid), 0, 0, 0, 4, 'f', 'r', 'e', 'e'), nil, ErrUnsupportedVideo},
+		{"64 bit size past the end", append(bytes.Clone(valid), hugeBox...), nil, ErrUnsupportedVideo},
+		{"64 bit size smaller than its header", append(bytes.Clone(valid), append(hugeBox[:8:8], 0, 0, 0, 0, 0, 0, 0, 12)...), nil, ErrUnsupportedVideo},
+		{"oversized movie header", videoFile("isom", oversizedMvhd, videoTrak("avc1")), nil, ErrUnsupportedVideo},
+		{"nesting too deep", videoFile("isom", mvhdBox(1000, 30000), nested), nil, ErrUnsupportedVideo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := InspectVideo(bytes.NewReader(tt.file), int64(len(tt.file)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.want != nil && *info != *tt.want {
+				t.Errorf("info = %+v, want %+v", info, tt.want)
+			}
+		})
+	}
+}
+
+func TestInspectVideoTooLarge(t *testing.T) {
+	file := videoFile("isom", mvhdBox(1000, 30000), videoTrak("avc1"))
+	if _, err := InspectVideo(bytes.NewReader(file), MaxVideoBytes+1); !errors.Is(err, ErrVideoTooLarge) {
+		t.Errorf("error = %v, want %v", err, ErrVideoTooLarge)
+	}
+}
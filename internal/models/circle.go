@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Circle is a private audience group owned by a user
+type Circle struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	UserID      string    `gorm:"type:numeric;not null;index" json:"user_id"` // Owner of the circle
+	User        User      `gorm:"foreignKey:UserID" json:"-"`                 // Belongs to User
+	Name        string    `gorm:"not null" json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relationships
+	Members []CircleMember `gorm:"foreignKey:CircleID" json:"-"` // One-to-many (Circle -> Members)
+}
+
+// CircleMember links a user to a circle
+type CircleMember struct {
+	CircleID uuid.UUID `gorm:"type:uuid;primaryKey" json:"circle_id"`          // Foreign key to Circle
+	MemberID string    `gorm:"type:numeric;primaryKey;index" json:"member_id"` // Foreign key to User
+	Member   User      `gorm:"foreignKey:MemberID" json:"-"`                   // Belongs to User
+	AddedAt  time.Time `gorm:"autoCreateTime" json:"added_at"`
+}
@@ -9,7 +9,7 @@ import (
 	"github.com/google/uuid"
 )
 
-// MentionedUser represents a user who is mentioned in the comment
+// MentionedUser represents a user who is mentioned in a post or a comment
 type MentionedUser struct {
 	UserID   string `gorm:"not null" json:"user_id"` // User ID of the mentioned user
 	UserName string `json:"user_name"`               // Username of the mentioned user
@@ -23,14 +23,20 @@ type Comment struct {
 	ID             uuid.UUID          `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
 	PostID         uuid.UUID          `gorm:"type:uuid;not null" json:"post_id"` // Foreign key to Post
 	Post           Post               `gorm:"foreignKey:PostID"`                 // Belongs to Post
+	ParentID       *uuid.UUID         `gorm:"type:uuid;index" json:"parent_id"`  // Top level comment when this comment is a reply
 	UserID         string             `gorm:"not null" json:"user_id"`           // Foreign key to User
 	User           User               `gorm:"foreignKey:UserID"`                 // Belongs to User
 	AuthorName     string             `json:"author_name"`
 	AuthorAvatar   string             `json:"author_avatar"`
 	Content        string             `gorm:"type:text;not null" json:"content"`
+	RepliesCount   int                `gorm:"default:0" json:"replies_count"`
+	LikesCount     int                `gorm:"default:0" json:"likes_count"`
 	CreatedAt      time.Time          `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt      time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
+	Edited         bool               `gorm:"default:false" json:"edited"` // Set when the author edits the comment, see CommentRevision
+	EditedAt       *time.Time         `json:"edited_at"`
 	MentionedUsers MentionedUserArray `gorm:"type:jsonb" json:"mentioned_users"` // Array of mentioned users stored as JSONB
+	YourLike       bool               `gorm:"-" json:"your_like"`                // Computed at runtime
 }
 
 // Scan implements the sql.Scanner interface for MentionedUserArray
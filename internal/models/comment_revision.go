@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CommentRevision keeps a previous version of an edited comment
+type CommentRevision struct {
+	ID             uuid.UUID          `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	CommentID      uuid.UUID          `gorm:"type:uuid;not null;index" json:"comment_id"` // Foreign key to Comment
+	Comment        Comment            `gorm:"foreignKey:CommentID" json:"-"`              // Belongs to Comment
+	Content        string             `gorm:"type:text;not null" json:"content"`
+	MentionedUsers MentionedUserArray `gorm:"type:jsonb" json:"mentioned_users"`
+	CreatedAt      time.Time          `gorm:"autoCreateTime" json:"created_at"` // When this version was replaced
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FeedEntry is a post of a home feed snapshot. The feed is scored once when its first page is
+// loaded and the following pages are read from the snapshot, so posts don't move between pages
+// when their likes or comments change.
+type FeedEntry struct {
+	ViewerID string    `gorm:"primaryKey" json:"viewer_id"`
+	AsOf     time.Time `gorm:"primaryKey;index" json:"as_of"` // Snapshot time, also held by the cursor of the next pages
+	PostID   uuid.UUID `gorm:"type:uuid;primaryKey" json:"post_id"`
+	Score    float64   `gorm:"not null" json:"score"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// Follow represents a one-way follow relationship between two users
+type Follow struct {
+	FollowerID  string    `gorm:"primaryKey;type:numeric" json:"follower_id"`        // User who follows
+	Follower    User      `gorm:"foreignKey:FollowerID" json:"-"`                    // Belongs to User
+	FollowingID string    `gorm:"primaryKey;type:numeric;index" json:"following_id"` // User being followed
+	Following   User      `gorm:"foreignKey:FollowingID" json:"-"`                   // Belongs to User
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`                  // Timestamp when the follow was created
+}
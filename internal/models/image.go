@@ -0,0 +1,53 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// Names of the resized versions generated for each uploaded image
+const (
+	ImageVariantThumbnail = "thumbnail"
+	ImageVariantFeed      = "feed"
+	ImageVariantFull      = "full"
+)
+
+// ImageVariant is a resized version of an uploaded image
+type ImageVariant struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// ImageVariants maps a variant name to its resized image
+type ImageVariants map[string]ImageVariant
+
+// Scan implements the sql.Scanner interface for ImageVariants
+func (v *ImageVariants) Scan(value interface{}) error {
+	if value == nil {
+		*v = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan image variants: expected []byte")
+	}
+
+	var variants ImageVariants
+	if err := json.Unmarshal(bytes, &variants); err != nil {
+		return errors.New("failed to unmarshal image variants: " + err.Error())
+	}
+
+	*v = variants
+	return nil
+}
+
+// Value implements the driver.Valuer interface for ImageVariants
+func (v ImageVariants) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
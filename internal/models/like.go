@@ -7,10 +7,11 @@ import (
 )
 
 type Like struct {
-	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
-	PostID    uuid.UUID `gorm:"type:uuid;not null" json:"post_id"` // Foreign key to Post
-	Post      Post      `gorm:"foreignKey:PostID"`                 // Belongs to Post
-	UserID    string    `gorm:"not null" json:"user_id"`           // Foreign key to User
-	User      User      `gorm:"foreignKey:UserID"`                 // Belongs to User
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`  // Timestamp when the like was created
+	ID        uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	PostID    uuid.UUID  `gorm:"type:uuid;not null" json:"post_id"` // Foreign key to Post, set to the comment's post for comment likes
+	Post      Post       `gorm:"foreignKey:PostID"`                 // Belongs to Post
+	CommentID *uuid.UUID `gorm:"type:uuid;index" json:"comment_id"` // Liked comment, nil when the like is on the post itself
+	UserID    string     `gorm:"not null" json:"user_id"`           // Foreign key to User
+	User      User       `gorm:"foreignKey:UserID"`                 // Belongs to User
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`  // Timestamp when the like was created
 }
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// LinkPreview holds the OpenGraph or Twitter card metadata of the first link of a post
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url"`
+	SiteName    string `json:"site_name"`
+}
+
+// Scan implements the sql.Scanner interface for LinkPreview
+func (l *LinkPreview) Scan(value interface{}) error {
+	if value == nil {
+		*l = LinkPreview{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan link preview: expected []byte")
+	}
+
+	if err := json.Unmarshal(bytes, l); err != nil {
+		return errors.New("failed to unmarshal link preview: " + err.Error())
+	}
+	return nil
+}
+
+// Value implements the driver.Valuer interface for LinkPreview
+func (l LinkPreview) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
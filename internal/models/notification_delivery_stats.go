@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationDeliveryStats counts what happened to the pushes of a notification. A notification
+// updated by new actors queues a push every time, so the counters keep growing with it.
+type NotificationDeliveryStats struct {
+	NotificationID uuid.UUID `gorm:"type:uuid;primaryKey" json:"notification_id"`
+	Queued         int64     `gorm:"not null;default:0" json:"queued"`
+	Sent           int64     `gorm:"not null;default:0" json:"sent"`      // Accepted by the provider
+	Delivered      int64     `gorm:"not null;default:0" json:"delivered"` // Confirmed by a receipt
+	Failed         int64     `gorm:"not null;default:0" json:"failed"`    // Dead pushes and failed receipts
+	InvalidTokens  int64     `gorm:"not null;default:0" json:"invalid_tokens"`
+	Skipped        int64     `gorm:"not null;default:0" json:"skipped"` // Held back by the preferences, during quiet hours for example
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationChannels tells where the notifications of an action type are delivered
+type NotificationChannels struct {
+	InApp bool `json:"in_app"`
+	Push  bool `json:"push"`
+}
+
+// NotificationTypeSettings holds the channels of each action type the user changed, action
+// types missing from it use both channels
+type NotificationTypeSettings map[string]NotificationChannels
+
+// NotificationPreferences are the notification settings of a user. Users without saved
+// preferences get every notification.
+type NotificationPreferences struct {
+	UserID            string                   `gorm:"primaryKey" json:"user_id"`
+	Types             NotificationTypeSettings `gorm:"type:jsonb" json:"types"`
+	QuietHoursEnabled bool                     `json:"quiet_hours_enabled"`
+	QuietHoursStart   string                   `json:"quiet_hours_start"` // "22:00", in the user's timezone
+	QuietHoursEnd     string                   `json:"quiet_hours_end"`   // "07:00", may be on the next day
+	Timezone          string                   `json:"timezone"`          // IANA name such as "Asia/Baghdad"
+	UpdatedAt         time.Time                `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// Channels returns the channels of an action type, both are on unless the user turned them off
+func (p NotificationPreferences) Channels(actionType string) NotificationChannels {
+	if channels, ok := p.Types[actionType]; ok {
+		return channels
+	}
+	return NotificationChannels{InApp: true, Push: true}
+}
+
+// MutedPost stops the notifications about a post for a user
+type MutedPost struct {
+	UserID    string    `gorm:"primaryKey" json:"user_id"`
+	PostID    uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"post_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Scan implements the sql.Scanner interface for NotificationTypeSettings
+func (s *NotificationTypeSettings) Scan(value interface{}) error {
+	if value == nil {
+		*s = NotificationTypeSettings{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan notification settings: expected []byte")
+	}
+
+	var settings NotificationTypeSettings
+	if err := json.Unmarshal(bytes, &settings); err != nil {
+		return errors.New("failed to unmarshal notification settings: " + err.Error())
+	}
+
+	*s = settings
+	return nil
+}
+
+// Value implements the driver.Valuer interface for NotificationTypeSettings
+func (s NotificationTypeSettings) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
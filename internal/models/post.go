@@ -1,30 +1,61 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
+// Share states control who can see a post
+const (
+	ShareStatePublic  = "Public"  // Everyone
+	ShareStatePrivate = "Private" // The author and their followers
+	ShareStateOnlyMe  = "Only me" // The author only
+	ShareStateCircles = "Circles" // The author and the members of the target circles
+)
+
+// ShareStates is the fixed set of share states a post can have
+var ShareStates = []string{ShareStatePublic, ShareStatePrivate, ShareStateOnlyMe, ShareStateCircles}
+
+// NormalizeShareState returns the canonical spelling of a share state and whether it is valid
+func NormalizeShareState(shareState string) (string, bool) {
+	for _, state := range ShareStates {
+		if strings.EqualFold(strings.TrimSpace(shareState), state) {
+			return state, true
+		}
+	}
+	return "", false
+}
+
 type Post struct {
-	ID             uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
-	AuthorID       string         `json:"author_id"`           // Foreign key to User
-	Author         User           `gorm:"foreignKey:AuthorID"` // Belongs to User
-	AuthorName     string         `json:"author_name"`
-	AuthorAvatar   string         `json:"author_avatar"`
-	Body           string         `json:"body"`
-	ImageURL       string         `json:"image_url"`
-	ShareState     string         `gorm:"default:Public" json:"share_state"`
-	LikesCount     int            `gorm:"default:0" json:"likes_count"`
-	CommentsCount  int            `gorm:"default:0" json:"comments_count"`
-	Hashtags       pq.StringArray `gorm:"type:text[]" json:"hashtags"`
-	MentionedUsers pq.Int32Array  `gorm:"type:int[]" json:"mentioned_users"`
-	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
-	YourLike       bool           `json:"your_like"` // Computed at runtime
+	ID             uuid.UUID          `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	AuthorID       string             `json:"author_id"`           // Foreign key to User
+	Author         User               `gorm:"foreignKey:AuthorID"` // Belongs to User
+	AuthorName     string             `json:"author_name"`
+	AuthorAvatar   string             `json:"author_avatar"`
+	Body           string             `json:"body"`
+	ImageURL       string             `json:"image_url"`                        // First image, kept for clients that don't read Media
+	ImageVariants  ImageVariants      `gorm:"type:jsonb" json:"image_variants"` // Resized versions of the first image, keyed by variant name
+	LinkPreview    *LinkPreview       `gorm:"type:jsonb" json:"link_preview"`   // Preview of the first link of the body
+	ShareState     string             `gorm:"default:Public" json:"share_state"`
+	CircleIDs      pq.StringArray     `gorm:"type:text[]" json:"circle_ids"` // Target circles when ShareState is Circles
+	LikesCount     int                `gorm:"default:0" json:"likes_count"`
+	CommentsCount  int                `gorm:"default:0" json:"comments_count"`
+	ResharesCount  int                `gorm:"default:0" json:"reshares_count"`
+	ResharedPostID *uuid.UUID         `gorm:"type:uuid;index" json:"reshared_post_id"`                       // Original post when this post is a reshare
+	Hashtags       pq.StringArray     `gorm:"type:text[];index:idx_posts_hashtags,type:gin" json:"hashtags"` // Normalized, see utils.NormalizeHashtag
+	MentionedUsers MentionedUserArray `gorm:"type:jsonb" json:"mentioned_users"`                             // Array of mentioned users stored as JSONB
+	CreatedAt      time.Time          `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
+	EditedAt       *time.Time         `json:"edited_at"`                             // Set when the author edits the post, see PostRevision
+	YourLike       bool               `json:"your_like"`                             // Computed at runtime
+	Score          float64            `gorm:"->;-:migration" json:"score,omitempty"` // Home feed rank, read from the feed snapshot
 
 	// Relationships
-	Comments []Comment `gorm:"foreignKey:PostID" json:"comments"` // One-to-many (Post -> Comments)
-	Likes    []Like    `gorm:"foreignKey:PostID" json:"likes"`    // One-to-many (Post -> Likes)
+	ResharedPost *Post       `gorm:"foreignKey:ResharedPostID" json:"reshared_post,omitempty"` // Belongs to the original Post
+	Media        []PostMedia `gorm:"foreignKey:PostID" json:"media"`                           // One-to-many (Post -> PostMedia), ordered by Position
+	Comments     []Comment   `gorm:"foreignKey:PostID" json:"comments"`                        // One-to-many (Post -> Comments)
+	Likes        []Like      `gorm:"foreignKey:PostID" json:"likes"`                           // One-to-many (Post -> Likes)
 }
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Types of the media attached to posts
+const (
+	MediaTypeImage = "image"
+	MediaTypeVideo = "video"
+)
+
+// PostMedia is a media attachment of a post, attachments are shown in Position order
+type PostMedia struct {
+	ID         uuid.UUID     `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	PostID     uuid.UUID     `gorm:"type:uuid;not null;index" json:"post_id"` // Foreign key to Post
+	Position   int           `gorm:"not null;default:0" json:"position"`
+	Type       string        `gorm:"not null;default:image" json:"type"`
+	URL        string        `gorm:"not null" json:"url"`
+	Width      int           `json:"width"`
+	Height     int           `json:"height"`
+	AltText    string        `json:"alt_text"`
+	DurationMs int           `json:"duration_ms,omitempty"`      // Length of a video
+	Variants   ImageVariants `gorm:"type:jsonb" json:"variants"` // Resized versions of the image or of the video poster frame, keyed by variant name
+	CreatedAt  time.Time     `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName keeps "media" uncountable
+func (PostMedia) TableName() string {
+	return "post_media"
+}
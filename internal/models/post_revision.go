@@ -0,0 +1,56 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// PostRevision keeps a previous version of an edited post
+type PostRevision struct {
+	ID         uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	PostID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"post_id"` // Foreign key to Post
+	Post       Post           `gorm:"foreignKey:PostID" json:"-"`              // Belongs to Post
+	Body       string         `json:"body"`
+	ImageURL   string         `json:"image_url"`
+	Media      MediaSnapshot  `gorm:"type:jsonb" json:"media"` // Attachments of this version
+	ShareState string         `json:"share_state"`
+	CircleIDs  pq.StringArray `gorm:"type:text[]" json:"circle_ids"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"created_at"` // When this version was replaced
+}
+
+// MediaSnapshot is a copy of the attachments of a post stored with a revision
+type MediaSnapshot []PostMedia
+
+// Scan implements the sql.Scanner interface for MediaSnapshot
+func (m *MediaSnapshot) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan media snapshot: expected []byte")
+	}
+
+	var snapshot MediaSnapshot
+	if err := json.Unmarshal(bytes, &snapshot); err != nil {
+		return errors.New("failed to unmarshal media snapshot: " + err.Error())
+	}
+
+	*m = snapshot
+	return nil
+}
+
+// Value implements the driver.Valuer interface for MediaSnapshot
+func (m MediaSnapshot) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
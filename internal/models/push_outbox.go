@@ -0,0 +1,86 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Delivery states of a queued push
+const (
+	PushStatusPending = "pending"
+	PushStatusSent    = "sent"
+	PushStatusDead    = "dead"    // Gave up, kept for inspection
+	PushStatusSkipped = "skipped" // Held back by the notification preferences of the user
+)
+
+// Receipt states of a sent push, pushes of providers without receipts keep an empty state
+const (
+	PushReceiptDelivered = "delivered"
+	PushReceiptFailed    = "failed"
+	PushReceiptExpired   = "expired" // The provider dropped the receipt before it was checked
+)
+
+// PushOutbox is a push notification waiting to be delivered. It is written in the same
+// transaction as its notification and delivered by a background worker.
+type PushOutbox struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	NotificationID uuid.UUID  `gorm:"type:uuid;index" json:"notification_id"`
+	UserID         string     `gorm:"index;not null" json:"user_id"`
+	ActionType     string     `json:"action_type"` // Last action of the notification, preferences are checked again before sending
+	Token          string     `gorm:"not null" json:"token"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`
+	Data           PushData   `gorm:"type:jsonb" json:"data"`
+	Status         string     `gorm:"index:idx_push_outbox_due,priority:1;not null;default:'pending'" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"index:idx_push_outbox_due,priority:2" json:"next_attempt_at"`
+	LastError      string     `json:"last_error"`
+	Provider       string     `json:"provider"`
+	TicketID       string     `json:"ticket_id"`
+	SentAt         *time.Time `json:"sent_at"`
+	ReceiptStatus  string     `gorm:"not null;default:''" json:"receipt_status"`
+	ReceiptError   string     `json:"receipt_error"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName keeps the outbox in a single "push_outbox" table
+func (PushOutbox) TableName() string {
+	return "push_outbox"
+}
+
+// PushData is the custom data sent along a push
+type PushData map[string]string
+
+// Scan implements the sql.Scanner interface for PushData
+func (d *PushData) Scan(value interface{}) error {
+	if value == nil {
+		*d = PushData{}
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan push data: expected []byte")
+	}
+
+	var data PushData
+	if err := json.Unmarshal(bytes, &data); err != nil {
+		return errors.New("failed to unmarshal push data: " + err.Error())
+	}
+
+	*d = data
+	return nil
+}
+
+// Value implements the driver.Valuer interface for PushData
+func (d PushData) Value() (driver.Value, error) {
+	if len(d) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(d)
+}
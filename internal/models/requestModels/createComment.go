@@ -6,3 +6,8 @@ type CreateCommentRequestBody struct {
 	Content        string                 `json:"content"`
 	MentionedUsers []models.MentionedUser `json:"mentioned_users"`
 }
+
+type EditCommentRequestBody struct {
+	Content        string                 `json:"content"`
+	MentionedUsers []models.MentionedUser `json:"mentioned_users"` // Replaces the structured mentions when sent
+}
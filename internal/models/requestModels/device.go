@@ -0,0 +1,8 @@
+package requestModels
+
+type RegisterDeviceRequestBody struct {
+	Token      string `json:"token"`
+	Platform   string `json:"platform"` // ios, android or web, guessed from the token when empty
+	AppVersion string `json:"app_version"`
+	Locale     string `json:"locale"`
+}
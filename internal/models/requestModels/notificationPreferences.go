@@ -0,0 +1,18 @@
+package requestModels
+
+type UpdateNotificationPreferencesRequestBody struct {
+	Types      map[string]NotificationChannelsRequestBody `json:"types"`       // Only the listed action types change
+	QuietHours *QuietHoursRequestBody                     `json:"quiet_hours"` // Replaces the quiet hours when sent
+}
+
+type NotificationChannelsRequestBody struct {
+	InApp *bool `json:"in_app"`
+	Push  *bool `json:"push"`
+}
+
+type QuietHoursRequestBody struct {
+	Enabled  bool   `json:"enabled"`
+	Start    string `json:"start"` // "22:00"
+	End      string `json:"end"`   // "07:00"
+	Timezone string `json:"timezone"`
+}
@@ -4,6 +4,9 @@ import (
 	"time"
 )
 
+// RoleAdmin is the role of the users allowed on the admin endpoints
+const RoleAdmin = "admin"
+
 type User struct {
 	ID            string    `json:"id" gorm:"primaryKey;type:numeric"`
 	Username      string    `json:"username" gorm:"not null"`
@@ -11,7 +14,6 @@ type User struct {
 	ProfileAvatar string    `json:"profile_avatar"`
 	ProfileCover  string    `json:"profile_cover"`
 	Bio           string    `json:"bio"`
-	PushToken     string    `json:"push_token"`
 	UserLang      string    `json:"user_lang" gorm:"default:'en'"`
 	Status        string    `json:"status" gorm:"default:'active'"`
 	Role          string    `json:"role" gorm:"default:'user'"`
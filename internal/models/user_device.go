@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Platforms of the registered devices
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWeb     = "web"
+)
+
+// UserDevice is a device a user receives push notifications on. A token belongs to a single
+// device, registering it for another user moves the device to that user.
+type UserDevice struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     string    `gorm:"index;not null" json:"user_id"`
+	Token      string    `gorm:"uniqueIndex;not null" json:"token"`
+	Platform   string    `json:"platform"`
+	AppVersion string    `json:"app_version"`
+	Locale     string    `json:"locale"`
+	LastSeenAt time.Time `gorm:"not null" json:"last_seen_at"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -0,0 +1,167 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	APNsProductionEndpoint  = "https://api.push.apple.com"
+	APNsDevelopmentEndpoint = "https://api.sandbox.push.apple.com"
+
+	// Apple rejects provider tokens older than an hour and throttles refreshes under 20 minutes
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+// APNsConfig holds the token based authentication settings of APNs
+type APNsConfig struct {
+	KeyFile    string // .p8 signing key downloaded from the Apple developer account
+	KeyID      string
+	TeamID     string
+	Topic      string // Bundle ID of the app
+	Production bool
+}
+
+// APNsSender sends pushes to iOS devices through the HTTP/2 APNs API
+type APNsSender struct {
+	Endpoint string
+	Client   *http.Client
+
+	config     APNsConfig
+	privateKey *ecdsa.PrivateKey
+
+	mu          sync.Mutex
+	bearer      string
+	bearerIssue time.Time
+}
+
+// NewAPNsSender creates a sender signing its requests with the configured key
+func NewAPNsSender(config APNsConfig) (*APNsSender, error) {
+	if config.KeyFile == "" || config.KeyID == "" || config.TeamID == "" || config.Topic == "" {
+		return nil, fmt.Errorf("APNs needs a key file, a key ID, a team ID and a topic")
+	}
+
+	keyPEM, err := os.ReadFile(config.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read APNs key: %w", err)
+	}
+	privateKey, err := jwt.ParseECPrivateKeyFromPEM(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APNs key: %w", err)
+	}
+
+	endpoint := APNsDevelopmentEndpoint
+	if config.Production {
+		endpoint = APNsProductionEndpoint
+	}
+
+	return &APNsSender{
+		Endpoint: endpoint,
+		// TLS connections negotiate HTTP/2, which APNs requires
+		Client:     &http.Client{Timeout: 10 * time.Second},
+		config:     config,
+		privateKey: privateKey,
+	}, nil
+}
+
+func (s *APNsSender) Send(ctx context.Context, message Message) (Result, error) {
+	bearer, err := s.providerToken()
+	if err != nil {
+		return Result{}, err
+	}
+
+	// Custom data sits next to the aps dictionary
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{
+				"title": message.Title,
+				"body":  message.Body,
+			},
+			"sound": "default",
+		},
+	}
+	for key, value := range message.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Endpoint+"/3/device/"+message.Token, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+bearer)
+	req.Header.Set("apns-topic", s.config.Topic)
+	req.Header.Set("apns-push-type", "alert")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("apns request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var reason struct {
+			Reason string `json:"reason"`
+		}
+		responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		_ = json.Unmarshal(responseBody, &reason)
+
+		switch reason.Reason {
+		case "BadDeviceToken", "Unregistered", "DeviceTokenNotForTopic":
+			return Result{}, fmt.Errorf("%w: %s", ErrInvalidToken, reason.Reason)
+		case "ExpiredProviderToken", "InvalidProviderToken":
+			s.resetProviderToken()
+		}
+		return Result{}, fmt.Errorf("apns returned %s: %s", resp.Status, reason.Reason)
+	}
+
+	return Result{Provider: TokenTypeAPNs, TicketID: resp.Header.Get("apns-id")}, nil
+}
+
+// providerToken returns the signed JWT authenticating the requests, it is reused while it is fresh
+func (s *APNsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.bearer != "" && time.Since(s.bearerIssue) < apnsTokenLifetime {
+		return s.bearer, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": s.config.TeamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = s.config.KeyID
+
+	bearer, err := token.SignedString(s.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign APNs provider token: %w", err)
+	}
+
+	s.bearer, s.bearerIssue = bearer, now
+	return bearer, nil
+}
+
+func (s *APNsSender) resetProviderToken() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bearer = ""
+}
@@ -0,0 +1,160 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newAPNsTestSender creates a sender with a fresh signing key pointed to the handler
+func newAPNsTestSender(t *testing.T, handler http.HandlerFunc) (*APNsSender, *ecdsa.PublicKey) {
+	t.Helper()
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "AuthKey_KEY123.p8")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	sender, err := NewAPNsSender(APNsConfig{KeyFile: keyFile, KeyID: "KEY123", TeamID: "TEAM123", Topic: "com.example.app"})
+	if err != nil {
+		t.Fatalf("NewAPNsSender: %v", err)
+	}
+	if sender.Endpoint != APNsDevelopmentEndpoint {
+		t.Errorf("Endpoint = %s, want the sandbox", sender.Endpoint)
+	}
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	sender.Endpoint = server.URL
+	sender.Client = server.Client()
+	return sender, &privateKey.PublicKey
+}
+
+func TestAPNsSend(t *testing.T) {
+	var publicKey *ecdsa.PublicKey
+	sender, publicKey := newAPNsTestSender(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/3/device/"+testAPNsToken {
+			t.Errorf("path = %s, want the device token", r.URL.Path)
+		}
+		if r.Header.Get("apns-topic") != "com.example.app" || r.Header.Get("apns-push-type") != "alert" {
+			t.Errorf("apns-topic = %q, apns-push-type = %q", r.Header.Get("apns-topic"), r.Header.Get("apns-push-type"))
+		}
+
+		// The provider token is an ES256 JWT issued by the team and naming the key
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "bearer ")
+		if !ok {
+			t.Errorf("Authorization = %q, want a bearer token", r.Header.Get("Authorization"))
+		}
+		claims := jwt.MapClaims{}
+		token, err := jwt.ParseWithClaims(bearer, claims, func(token *jwt.Token) (interface{}, error) {
+			return publicKey, nil
+		}, jwt.WithValidMethods([]string{"ES256"}))
+		if err != nil {
+			t.Errorf("invalid provider token: %v", err)
+		} else if token.Header["kid"] != "KEY123" || claims["iss"] != "TEAM123" {
+			t.Errorf("provider token kid = %v, iss = %v", token.Header["kid"], claims["iss"])
+		}
+
+		var payload struct {
+			APS struct {
+				Alert map[string]string `json:"alert"`
+			} `json:"aps"`
+			ReferenceID string `json:"reference_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("invalid payload: %v", err)
+		}
+		if payload.APS.Alert["title"] != "New like" || payload.APS.Alert["body"] != "Sara liked your post" || payload.ReferenceID != "post-1" {
+			t.Errorf("payload = %+v, want the alert and the custom data", payload)
+		}
+
+		w.Header().Set("apns-id", "EC1BF194-B3B2-424A-89A9-5A918A6E6B5A")
+	})
+
+	result, err := sender.Send(context.Background(), Message{
+		Token: testAPNsToken,
+		Title: "New like",
+		Body:  "Sara liked your post",
+		Data:  map[string]string{"reference_id": "post-1", "aps": "ignored"},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.Provider != TokenTypeAPNs || result.TicketID != "EC1BF194-B3B2-424A-89A9-5A918A6E6B5A" {
+		t.Errorf("result = %+v, want the apns-id", result)
+	}
+}
+
+func TestAPNsSendReasons(t *testing.T) {
+	tests := []struct {
+		status           int
+		reason           string
+		wantInvalid      bool
+		wantTokenDropped bool
+	}{
+		{http.StatusBadRequest, "BadDeviceToken", true, false},
+		{http.StatusGone, "Unregistered", true, false},
+		{http.StatusBadRequest, "DeviceTokenNotForTopic", true, false},
+		{http.StatusBadRequest, "PayloadTooLarge", false, false},
+		{http.StatusTooManyRequests, "TooManyRequests", false, false},
+		{http.StatusForbidden, "ExpiredProviderToken", false, true},
+		{http.StatusForbidden, "InvalidProviderToken", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			sender, _ := newAPNsTestSender(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprintf(w, `{"reason": %q}`, tt.reason)
+			})
+
+			_, err := sender.Send(context.Background(), Message{Token: testAPNsToken})
+			if err == nil {
+				t.Fatal("Send succeeded")
+			}
+			if got := errors.Is(err, ErrInvalidToken); got != tt.wantInvalid {
+				t.Errorf("errors.Is(%v, ErrInvalidToken) = %v, want %v", err, got, tt.wantInvalid)
+			}
+			if dropped := sender.bearer == ""; dropped != tt.wantTokenDropped {
+				t.Errorf("provider token dropped = %v, want %v", dropped, tt.wantTokenDropped)
+			}
+		})
+	}
+}
+
+func TestAPNsProviderTokenReused(t *testing.T) {
+	var bearers []string
+	sender, _ := newAPNsTestSender(t, func(w http.ResponseWriter, r *http.Request) {
+		bearers = append(bearers, r.Header.Get("Authorization"))
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := sender.Send(context.Background(), Message{Token: testAPNsToken}); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	if len(bearers) != 2 || bearers[0] != bearers[1] {
+		t.Errorf("provider tokens %v, want the same token for both pushes", bearers)
+	}
+}
@@ -0,0 +1,141 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Push and receipts APIs of Expo
+const (
+	DefaultExpoEndpoint         = "https://exp.host/--/api/v2/push/send"
+	DefaultExpoReceiptsEndpoint = "https://exp.host/--/api/v2/push/getReceipts"
+
+	// MaxExpoReceiptIDs is the most receipts Expo returns for a single request
+	MaxExpoReceiptIDs = 1000
+)
+
+// ExpoSender sends pushes to Expo push tokens and checks their receipts
+type ExpoSender struct {
+	Endpoint         string
+	ReceiptsEndpoint string
+	AccessToken      string // Optional, required when enhanced push security is enabled
+	Client           *http.Client
+}
+
+// NewExpoSender creates a sender for the Expo push API
+func NewExpoSender(accessToken string) *ExpoSender {
+	return &ExpoSender{
+		Endpoint:         DefaultExpoEndpoint,
+		ReceiptsEndpoint: DefaultExpoReceiptsEndpoint,
+		AccessToken:      accessToken,
+		Client:           &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// expoDeviceNotRegistered is the error of tickets and receipts sent to an uninstalled app
+const expoDeviceNotRegistered = "DeviceNotRegistered"
+
+type expoTicket struct {
+	Status  string `json:"status"`
+	ID      string `json:"id"`
+	Message string `json:"message"`
+	Details struct {
+		Error string `json:"error"`
+	} `json:"details"`
+}
+
+func (s *ExpoSender) Send(ctx context.Context, message Message) (Result, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"to":    message.Token,
+		"title": message.Title,
+		"body":  message.Body,
+		"data":  message.Data,
+		"sound": "default",
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	// A single message gets a single ticket, errors about the token itself are reported in it
+	var response struct {
+		Data expoTicket `json:"data"`
+	}
+	if err := s.post(ctx, s.Endpoint, payload, &response); err != nil {
+		return Result{}, err
+	}
+
+	ticket := response.Data
+	if ticket.Status != "ok" {
+		if ticket.Details.Error == expoDeviceNotRegistered {
+			return Result{}, fmt.Errorf("%w: %s", ErrInvalidToken, ticket.Message)
+		}
+		return Result{}, fmt.Errorf("expo rejected the push: %s %s", ticket.Details.Error, ticket.Message)
+	}
+
+	return Result{Provider: TokenTypeExpo, TicketID: ticket.ID}, nil
+}
+
+// CheckReceipts looks up the receipts of the given tickets. Receipts that are not ready yet are
+// missing from the result, Expo keeps them for 24 hours.
+func (s *ExpoSender) CheckReceipts(ctx context.Context, ticketIDs []string) (map[string]Receipt, error) {
+	if len(ticketIDs) > MaxExpoReceiptIDs {
+		return nil, fmt.Errorf("at most %d receipts can be checked at once", MaxExpoReceiptIDs)
+	}
+
+	payload, err := json.Marshal(map[string][]string{"ids": ticketIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data map[string]expoTicket `json:"data"`
+	}
+	if err := s.post(ctx, s.ReceiptsEndpoint, payload, &response); err != nil {
+		return nil, err
+	}
+
+	receipts := make(map[string]Receipt, len(response.Data))
+	for id, receipt := range response.Data {
+		receipts[id] = Receipt{
+			Delivered:    receipt.Status == "ok",
+			Error:        receipt.Details.Error,
+			Message:      receipt.Message,
+			InvalidToken: receipt.Details.Error == expoDeviceNotRegistered,
+		}
+	}
+	return receipts, nil
+}
+
+// post sends a JSON request to Expo and decodes the response into out
+func (s *ExpoSender) post(ctx context.Context, endpoint string, payload []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if s.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.AccessToken)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("expo request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("expo returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode expo response: %w", err)
+	}
+	return nil
+}
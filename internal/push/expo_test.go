@@ -0,0 +1,142 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newExpoTestSender points an Expo sender to a stand-in server answering with the given body
+func newExpoTestSender(t *testing.T, status int, response string, requests *[]map[string]interface{}) *ExpoSender {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer access-token" {
+			t.Errorf("Authorization = %q, want the access token", got)
+		}
+		var request map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		if requests != nil {
+			*requests = append(*requests, request)
+		}
+		w.WriteHeader(status)
+		fmt.Fprint(w, response)
+	}))
+	t.Cleanup(server.Close)
+
+	sender := NewExpoSender("access-token")
+	sender.Endpoint = server.URL + "/send"
+	sender.ReceiptsEndpoint = server.URL + "/getReceipts"
+	sender.Client = server.Client()
+	return sender
+}
+
+func TestExpoSend(t *testing.T) {
+	var requests []map[string]interface{}
+	sender := newExpoTestSender(t, http.StatusOK, `{"data": {"status": "ok", "id": "ticket-1"}}`, &requests)
+
+	result, err := sender.Send(context.Background(), Message{
+		Token: testExpoToken,
+		Title: "New comment",
+		Body:  "Sara commented on your post",
+		Data:  map[string]string{"reference_id": "post-1"},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if result.Provider != TokenTypeExpo || result.TicketID != "ticket-1" {
+		t.Errorf("result = %+v, want the expo ticket", result)
+	}
+
+	request := requests[0]
+	if request["to"] != testExpoToken || request["title"] != "New comment" || request["body"] != "Sara commented on your post" {
+		t.Errorf("request = %v, want the token, title and body", request)
+	}
+	if data, _ := request["data"].(map[string]interface{}); data["reference_id"] != "post-1" {
+		t.Errorf("request data = %v, want the reference ID", request["data"])
+	}
+}
+
+func TestExpoSendTicketErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		response    string
+		wantInvalid bool
+	}{
+		{
+			name:        "device not registered",
+			status:      http.StatusOK,
+			response:    `{"data": {"status": "error", "message": "not a registered push token", "details": {"error": "DeviceNotRegistered"}}}`,
+			wantInvalid: true,
+		},
+		{
+			name:     "message too big",
+			status:   http.StatusOK,
+			response: `{"data": {"status": "error", "message": "payload too large", "details": {"error": "MessageTooBig"}}}`,
+		},
+		{
+			name:     "server error",
+			status:   http.StatusInternalServerError,
+			response: `{"errors": [{"code": "INTERNAL_SERVER_ERROR"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := newExpoTestSender(t, tt.status, tt.response, nil)
+
+			_, err := sender.Send(context.Background(), Message{Token: testExpoToken})
+			if err == nil {
+				t.Fatal("Send succeeded")
+			}
+			if got := errors.Is(err, ErrInvalidToken); got != tt.wantInvalid {
+				t.Errorf("errors.Is(%v, ErrInvalidToken) = %v, want %v", err, got, tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestExpoCheckReceipts(t *testing.T) {
+	var requests []map[string]interface{}
+	sender := newExpoTestSender(t, http.StatusOK, `{"data": {
+		"ticket-1": {"status": "ok"},
+		"ticket-2": {"status": "error", "message": "uninstalled", "details": {"error": "DeviceNotRegistered"}},
+		"ticket-3": {"status": "error", "message": "rate limited", "details": {"error": "MessageRateExceeded"}}
+	}}`, &requests)
+
+	receipts, err := sender.CheckReceipts(context.Background(), []string{"ticket-1", "ticket-2", "ticket-3", "ticket-4"})
+	if err != nil {
+		t.Fatalf("CheckReceipts: %v", err)
+	}
+	if ids, _ := requests[0]["ids"].([]interface{}); len(ids) != 4 {
+		t.Errorf("request ids = %v, want the 4 tickets", requests[0]["ids"])
+	}
+
+	want := map[string]Receipt{
+		"ticket-1": {Delivered: true},
+		"ticket-2": {Error: "DeviceNotRegistered", Message: "uninstalled", InvalidToken: true},
+		"ticket-3": {Error: "MessageRateExceeded", Message: "rate limited"},
+	}
+	if len(receipts) != len(want) {
+		t.Errorf("got %d receipts, want %d, tickets that are not ready are left out", len(receipts), len(want))
+	}
+	for id, receipt := range want {
+		if receipts[id] != receipt {
+			t.Errorf("receipt %s = %+v, want %+v", id, receipts[id], receipt)
+		}
+	}
+}
+
+func TestExpoCheckReceiptsLimit(t *testing.T) {
+	sender := newExpoTestSender(t, http.StatusOK, `{"data": {}}`, nil)
+
+	if _, err := sender.CheckReceipts(context.Background(), make([]string, MaxExpoReceiptIDs+1)); err == nil {
+		t.Error("CheckReceipts accepted more than MaxExpoReceiptIDs tickets")
+	}
+}
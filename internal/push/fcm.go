@@ -0,0 +1,200 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	fcmScope           = "https://www.googleapis.com/auth/firebase.messaging"
+	fcmEndpointPattern = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+)
+
+// FCMServiceAccount holds the fields of a Google service account key file used by FCM
+type FCMServiceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCMSender sends pushes through the Firebase Cloud Messaging HTTP v1 API. It signs in with a
+// service account and caches the OAuth access token until shortly before it expires.
+type FCMSender struct {
+	Endpoint string
+	Client   *http.Client
+
+	account    FCMServiceAccount
+	privateKey *rsa.PrivateKey
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMSenderFromFile creates a sender from a service account key file
+func NewFCMSenderFromFile(path string) (*FCMSender, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FCM service account: %w", err)
+	}
+
+	var account FCMServiceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("failed to decode FCM service account: %w", err)
+	}
+	return NewFCMSender(account)
+}
+
+// NewFCMSender creates a sender for the project of the service account
+func NewFCMSender(account FCMServiceAccount) (*FCMSender, error) {
+	if account.ProjectID == "" || account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("FCM service account needs project_id, client_email and private_key")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid FCM private key: %w", err)
+	}
+
+	return &FCMSender{
+		Endpoint:   fmt.Sprintf(fcmEndpointPattern, account.ProjectID),
+		Client:     &http.Client{Timeout: 10 * time.Second},
+		account:    account,
+		privateKey: privateKey,
+	}, nil
+}
+
+func (s *FCMSender) Send(ctx context.Context, message Message) (Result, error) {
+	accessToken, err := s.token(ctx)
+	if err != nil {
+		return Result{}, err
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token": message.Token,
+			"notification": map[string]string{
+				"title": message.Title,
+				"body":  message.Body,
+			},
+			"data": message.Data,
+		},
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("fcm request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		// UNREGISTERED and invalid registration tokens mean the app is gone from the device
+		if resp.StatusCode == http.StatusNotFound || bytes.Contains(body, []byte("UNREGISTERED")) ||
+			(resp.StatusCode == http.StatusBadRequest && bytes.Contains(body, []byte("registration token"))) {
+			return Result{}, fmt.Errorf("%w: %s", ErrInvalidToken, bytes.TrimSpace(body))
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			s.resetToken()
+		}
+		return Result{}, fmt.Errorf("fcm returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	var response struct {
+		Name string `json:"name"` // projects/<project>/messages/<id>
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Result{}, fmt.Errorf("failed to decode fcm response: %w", err)
+	}
+
+	return Result{Provider: TokenTypeFCM, TicketID: response.Name}, nil
+}
+
+// token returns a valid OAuth access token, exchanging a signed assertion for a new one when needed
+func (s *FCMSender) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && time.Now().Before(s.expiresAt) {
+		return s.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   s.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(s.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign FCM assertion: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fcm token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fcm token request returned %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.AccessToken == "" {
+		return "", fmt.Errorf("invalid fcm token response")
+	}
+
+	// Refresh a minute early so a token never expires mid request
+	s.accessToken = token.AccessToken
+	s.expiresAt = now.Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return s.accessToken, nil
+}
+
+func (s *FCMSender) resetToken() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accessToken = ""
+}
@@ -0,0 +1,194 @@
+package push
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fcmTestServer stands in for the Google token endpoint and the FCM send API
+type fcmTestServer struct {
+	*httptest.Server
+	publicKey *rsa.PublicKey
+
+	tokenRequests int
+	sendStatus    int
+	sendResponse  string
+}
+
+func newFCMTestSender(t *testing.T) (*FCMSender, *fcmTestServer) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+
+	server := &fcmTestServer{publicKey: &privateKey.PublicKey, sendStatus: http.StatusOK,
+		sendResponse: `{"name": "projects/demo/messages/0:1500415314455276%31bd1c9631bd1c96"}`}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			server.tokenRequests++
+			server.checkAssertion(t, r)
+			fmt.Fprintf(w, `{"access_token": "access-%d", "expires_in": 3600, "token_type": "Bearer"}`, server.tokenRequests)
+		case "/v1/projects/demo/messages:send":
+			if got, want := r.Header.Get("Authorization"), fmt.Sprintf("Bearer access-%d", server.tokenRequests); got != want {
+				t.Errorf("Authorization = %q, want %q", got, want)
+			}
+			w.WriteHeader(server.sendStatus)
+			fmt.Fprint(w, server.sendResponse)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	sender, err := NewFCMSender(FCMServiceAccount{
+		ProjectID:   "demo",
+		ClientEmail: "push@demo.iam.gserviceaccount.com",
+		PrivateKey:  string(keyPEM),
+		TokenURI:    server.URL + "/token",
+	})
+	if err != nil {
+		t.Fatalf("NewFCMSender: %v", err)
+	}
+	if sender.Endpoint != "https://fcm.googleapis.com/v1/projects/demo/messages:send" {
+		t.Errorf("Endpoint = %s, want the send API of the project", sender.Endpoint)
+	}
+	sender.Endpoint = server.URL + "/v1/projects/demo/messages:send"
+	sender.Client = server.Client()
+	return sender, server
+}
+
+// checkAssertion verifies the JWT bearer grant signed with the service account key
+func (s *fcmTestServer) checkAssertion(t *testing.T, r *http.Request) {
+	t.Helper()
+	if got := r.FormValue("grant_type"); got != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		t.Errorf("grant_type = %q, want the JWT bearer grant", got)
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(r.FormValue("assertion"), claims, func(token *jwt.Token) (interface{}, error) {
+		return s.publicKey, nil
+	}, jwt.WithValidMethods([]string{"RS256"}))
+	if err != nil {
+		t.Errorf("invalid assertion: %v", err)
+		return
+	}
+	if claims["iss"] != "push@demo.iam.gserviceaccount.com" || claims["scope"] != fcmScope || claims["aud"] != s.URL+"/token" {
+		t.Errorf("assertion claims = %v, want the service account, the FCM scope and the token URI", claims)
+	}
+}
+
+func TestFCMSend(t *testing.T) {
+	sender, server := newFCMTestSender(t)
+
+	for i := 0; i < 2; i++ {
+		result, err := sender.Send(context.Background(), Message{Token: testFCMToken, Title: "Hi", Data: map[string]string{"type": "like"}})
+		if err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+		if result.Provider != TokenTypeFCM || result.TicketID != "projects/demo/messages/0:1500415314455276%31bd1c9631bd1c96" {
+			t.Errorf("result = %+v, want the FCM message name", result)
+		}
+	}
+
+	// The access token is reused until it expires
+	if server.tokenRequests != 1 {
+		t.Errorf("token exchanged %d times, want once", server.tokenRequests)
+	}
+}
+
+func TestFCMSendErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		response    string
+		wantInvalid bool
+	}{
+		{
+			name:        "not found",
+			status:      http.StatusNotFound,
+			response:    `{"error": {"code": 404, "message": "Requested entity was not found.", "status": "NOT_FOUND"}}`,
+			wantInvalid: true,
+		},
+		{
+			name:   "unregistered",
+			status: http.StatusBadRequest,
+			response: `{"error": {"code": 400, "status": "INVALID_ARGUMENT", "details": [
+				{"@type": "type.googleapis.com/google.firebase.fcm.v1.FcmError", "errorCode": "UNREGISTERED"}]}}`,
+			wantInvalid: true,
+		},
+		{
+			name:        "invalid registration token",
+			status:      http.StatusBadRequest,
+			response:    `{"error": {"code": 400, "message": "The registration token is not a valid FCM registration token", "status": "INVALID_ARGUMENT"}}`,
+			wantInvalid: true,
+		},
+		{
+			name:     "other invalid argument",
+			status:   http.StatusBadRequest,
+			response: `{"error": {"code": 400, "message": "Invalid JSON payload received.", "status": "INVALID_ARGUMENT"}}`,
+		},
+		{
+			name:     "quota exceeded",
+			status:   http.StatusTooManyRequests,
+			response: `{"error": {"code": 429, "status": "RESOURCE_EXHAUSTED"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, server := newFCMTestSender(t)
+			server.sendStatus, server.sendResponse = tt.status, tt.response
+
+			_, err := sender.Send(context.Background(), Message{Token: testFCMToken})
+			if err == nil {
+				t.Fatal("Send succeeded")
+			}
+			if got := errors.Is(err, ErrInvalidToken); got != tt.wantInvalid {
+				t.Errorf("errors.Is(%v, ErrInvalidToken) = %v, want %v", err, got, tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestFCMSendUnauthorizedRefreshesToken(t *testing.T) {
+	sender, server := newFCMTestSender(t)
+	server.sendStatus, server.sendResponse = http.StatusUnauthorized, `{"error": {"code": 401, "status": "UNAUTHENTICATED"}}`
+
+	if _, err := sender.Send(context.Background(), Message{Token: testFCMToken}); err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("error = %v, want a plain failure", err)
+	}
+
+	server.sendStatus, server.sendResponse = http.StatusOK, `{"name": "projects/demo/messages/2"}`
+	if _, err := sender.Send(context.Background(), Message{Token: testFCMToken}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if server.tokenRequests != 2 {
+		t.Errorf("token exchanged %d times, want a new token after the 401", server.tokenRequests)
+	}
+}
+
+func TestFCMTokenExchangeFailure(t *testing.T) {
+	sender, server := newFCMTestSender(t)
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+	})
+
+	if _, err := sender.Send(context.Background(), Message{Token: testFCMToken}); err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("error = %v, want a token exchange failure", err)
+	}
+}
@@ -0,0 +1,57 @@
+package push
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// RecordingSender keeps pushes in memory instead of delivering them, for tests and local development
+type RecordingSender struct {
+	mu       sync.Mutex
+	messages []Message
+
+	// Err, when set, is returned for every push after recording it
+	Err error
+}
+
+// NewRecordingSender creates an empty recording sender
+func NewRecordingSender() *RecordingSender {
+	return &RecordingSender{}
+}
+
+func (s *RecordingSender) Send(ctx context.Context, message Message) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = append(s.messages, message)
+	if s.Err != nil {
+		return Result{}, s.Err
+	}
+	return Result{Provider: DetectTokenType(message.Token), TicketID: fmt.Sprintf("recorded-%d", len(s.messages))}, nil
+}
+
+// CheckReceipts reports every recorded push as delivered
+func (s *RecordingSender) CheckReceipts(ctx context.Context, ticketIDs []string) (map[string]Receipt, error) {
+	receipts := make(map[string]Receipt, len(ticketIDs))
+	for _, id := range ticketIDs {
+		receipts[id] = Receipt{Delivered: true}
+	}
+	return receipts, nil
+}
+
+// Messages returns a copy of the recorded pushes in sending order
+func (s *RecordingSender) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Message(nil), s.messages...)
+}
+
+// Reset forgets the recorded pushes
+func (s *RecordingSender) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = nil
+}
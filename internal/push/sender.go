@@ -0,0 +1,98 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Message is a push notification for a single device
+type Message struct {
+	Token string
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// Result describes an accepted push
+type Result struct {
+	Provider string // Token type of the provider that took the push
+	TicketID string // Provider side ID of the push, used to look up delivery receipts
+}
+
+// PushSender delivers push notifications to one provider
+type PushSender interface {
+	Send(ctx context.Context, message Message) (Result, error)
+}
+
+// Receipt is the final delivery outcome of an accepted push
+type Receipt struct {
+	Delivered    bool
+	Error        string // Provider error code when the push was not delivered
+	Message      string
+	InvalidToken bool // The token should not be used again
+}
+
+// ReceiptChecker is implemented by providers that confirm the delivery of a push after accepting it
+type ReceiptChecker interface {
+	// CheckReceipts returns the receipts of the given ticket IDs, receipts that are not
+	// ready yet are missing from the result
+	CheckReceipts(ctx context.Context, ticketIDs []string) (map[string]Receipt, error)
+}
+
+// ErrInvalidToken is returned when the provider reports the token as invalid or unregistered,
+// the token should not be used again
+var ErrInvalidToken = errors.New("push token is invalid or no longer registered")
+
+// Token types, each one is served by its own provider
+const (
+	TokenTypeExpo = "expo"
+	TokenTypeFCM  = "fcm"
+	TokenTypeAPNs = "apns"
+)
+
+var (
+	expoTokenPattern = regexp.MustCompile(`^Expo(nent)?PushToken\[.+\]$`)
+	apnsTokenPattern = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+)
+
+// DetectTokenType tells which provider a device token belongs to. Expo tokens are wrapped in
+// ExponentPushToken[...], APNs tokens are 64 hexadecimal characters and anything else is
+// treated as an FCM registration token.
+func DetectTokenType(token string) string {
+	token = strings.TrimSpace(token)
+	switch {
+	case expoTokenPattern.MatchString(token):
+		return TokenTypeExpo
+	case apnsTokenPattern.MatchString(token):
+		return TokenTypeAPNs
+	default:
+		return TokenTypeFCM
+	}
+}
+
+// Router sends each push with the sender registered for the type of its token
+type Router struct {
+	senders map[string]PushSender
+}
+
+// NewRouter creates a router from token types to senders, types without a sender are rejected
+func NewRouter(senders map[string]PushSender) *Router {
+	return &Router{senders: senders}
+}
+
+func (r *Router) Send(ctx context.Context, message Message) (Result, error) {
+	tokenType := DetectTokenType(message.Token)
+	sender, ok := r.senders[tokenType]
+	if !ok || sender == nil {
+		return Result{}, fmt.Errorf("no push sender configured for %s tokens", tokenType)
+	}
+
+	result, err := sender.Send(ctx, message)
+	if result.Provider == "" {
+		result.Provider = tokenType
+	}
+	return result, err
+}
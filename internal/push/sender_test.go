@@ -0,0 +1,128 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const (
+	testExpoToken = "ExponentPushToken[xxxxxxxxxxxxxxxxxxxxxx]"
+	testAPNsToken = "740f4707bebcf74f9b7c25d48e3358945f6aa01da5ddb387462c7eaf61bb78ad"
+	testFCMToken  = "dQw4w9WgXcQ:APA91bHun4MxP5egoKMwt2KZFBaFUH-1RYqx"
+)
+
+func TestDetectTokenType(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{testExpoToken, TokenTypeExpo},
+		{"ExpoPushToken[xxxxxxxxxxxxxxxxxxxxxx]", TokenTypeExpo},
+		{"  " + testExpoToken + "\n", TokenTypeExpo},
+		{"ExponentPushToken[]", TokenTypeFCM},
+		{testAPNsToken, TokenTypeAPNs},
+		{strings.ToUpper(testAPNsToken), TokenTypeAPNs},
+		{testAPNsToken[:63], TokenTypeFCM},
+		{testAPNsToken + "0", TokenTypeFCM},
+		{testFCMToken, TokenTypeFCM},
+	}
+	for _, tt := range tests {
+		if got := DetectTokenType(tt.token); got != tt.want {
+			t.Errorf("DetectTokenType(%q) = %s, want %s", tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestRouterSend(t *testing.T) {
+	expo, fcm, apns := NewRecordingSender(), NewRecordingSender(), NewRecordingSender()
+	router := NewRouter(map[string]PushSender{
+		TokenTypeExpo: expo,
+		TokenTypeFCM:  fcm,
+		TokenTypeAPNs: apns,
+	})
+
+	tests := []struct {
+		token  string
+		sender *RecordingSender
+		want   string
+	}{
+		{testExpoToken, expo, TokenTypeExpo},
+		{testFCMToken, fcm, TokenTypeFCM},
+		{testAPNsToken, apns, TokenTypeAPNs},
+	}
+	for _, tt := range tests {
+		result, err := router.Send(context.Background(), Message{Token: tt.token, Title: "Hi"})
+		if err != nil {
+			t.Fatalf("Send(%s): %v", tt.token, err)
+		}
+		if result.Provider != tt.want || result.TicketID == "" {
+			t.Errorf("Send(%s) = %+v, want provider %s and a ticket", tt.token, result, tt.want)
+		}
+		if messages := tt.sender.Messages(); len(messages) != 1 || messages[0].Token != tt.token {
+			t.Errorf("%s sender recorded %+v, want the single push", tt.want, messages)
+		}
+	}
+}
+
+func TestRouterSendUnconfiguredProvider(t *testing.T) {
+	expo := NewRecordingSender()
+	router := NewRouter(map[string]PushSender{TokenTypeExpo: expo, TokenTypeAPNs: nil})
+
+	for _, token := range []string{testFCMToken, testAPNsToken} {
+		if _, err := router.Send(context.Background(), Message{Token: token}); err == nil {
+			t.Errorf("Send(%s) succeeded without a configured sender", token)
+		}
+	}
+	if messages := expo.Messages(); len(messages) != 0 {
+		t.Errorf("expo sender recorded %d pushes for other token types", len(messages))
+	}
+}
+
+func TestRouterSendError(t *testing.T) {
+	fcm := NewRecordingSender()
+	fcm.Err = ErrInvalidToken
+	router := NewRouter(map[string]PushSender{TokenTypeFCM: fcm})
+
+	result, err := router.Send(context.Background(), Message{Token: testFCMToken})
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("error = %v, want ErrInvalidToken", err)
+	}
+	// The provider is still reported so the failure can be attributed
+	if result.Provider != TokenTypeFCM {
+		t.Errorf("provider = %q, want %s", result.Provider, TokenTypeFCM)
+	}
+	if len(fcm.Messages()) != 1 {
+		t.Errorf("failed push was not recorded")
+	}
+}
+
+func TestRecordingSender(t *testing.T) {
+	sender := NewRecordingSender()
+
+	first, _ := sender.Send(context.Background(), Message{Token: testExpoToken, Title: "First"})
+	second, _ := sender.Send(context.Background(), Message{Token: testExpoToken, Title: "Second"})
+	if first.TicketID == second.TicketID {
+		t.Errorf("ticket IDs repeat: %s", first.TicketID)
+	}
+
+	receipts, err := sender.CheckReceipts(context.Background(), []string{first.TicketID, second.TicketID})
+	if err != nil {
+		t.Fatalf("CheckReceipts: %v", err)
+	}
+	for _, id := range []string{first.TicketID, second.TicketID} {
+		if !receipts[id].Delivered {
+			t.Errorf("receipt of %s = %+v, want delivered", id, receipts[id])
+		}
+	}
+
+	messages := sender.Messages()
+	if len(messages) != 2 || messages[0].Title != "First" || messages[1].Title != "Second" {
+		t.Errorf("Messages() = %+v, want both pushes in order", messages)
+	}
+	sender.Reset()
+	if len(sender.Messages()) != 0 {
+		t.Error("Reset kept the recorded pushes")
+	}
+}
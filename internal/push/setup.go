@@ -0,0 +1,68 @@
+package push
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// Sender is the push sender selected at startup
+var Sender PushSender
+
+// Receipts checks the receipts of the pushes sent through Expo, the only provider reporting
+// the delivery after accepting a push
+var Receipts ReceiptChecker
+
+// Setup selects the push providers from the environment. PUSH_SENDER=recording keeps pushes
+// in memory for local development, otherwise a router is built from the configured providers:
+// Expo is always available (EXPO_ACCESS_TOKEN is optional), FCM needs FCM_SERVICE_ACCOUNT_FILE
+// and APNs needs APNS_KEY_FILE, APNS_KEY_ID, APNS_TEAM_ID and APNS_TOPIC.
+func Setup() {
+	var err error
+	Sender, Receipts, err = newSenderFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to set up push notifications: %v", err)
+	}
+}
+
+func newSenderFromEnv() (PushSender, ReceiptChecker, error) {
+	switch mode := strings.ToLower(os.Getenv("PUSH_SENDER")); mode {
+	case "recording":
+		recording := NewRecordingSender()
+		return recording, recording, nil
+
+	case "":
+		expo := NewExpoSender(os.Getenv("EXPO_ACCESS_TOKEN"))
+		senders := map[string]PushSender{
+			TokenTypeExpo: expo,
+		}
+
+		if path := os.Getenv("FCM_SERVICE_ACCOUNT_FILE"); path != "" {
+			fcm, err := NewFCMSenderFromFile(path)
+			if err != nil {
+				return nil, nil, err
+			}
+			senders[TokenTypeFCM] = fcm
+		}
+
+		if keyFile := os.Getenv("APNS_KEY_FILE"); keyFile != "" {
+			apns, err := NewAPNsSender(APNsConfig{
+				KeyFile:    keyFile,
+				KeyID:      os.Getenv("APNS_KEY_ID"),
+				TeamID:     os.Getenv("APNS_TEAM_ID"),
+				Topic:      os.Getenv("APNS_TOPIC"),
+				Production: os.Getenv("APNS_PRODUCTION") == "true",
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			senders[TokenTypeAPNs] = apns
+		}
+
+		return NewRouter(senders), expo, nil
+
+	default:
+		return nil, nil, fmt.Errorf("unknown PUSH_SENDER: %s", mode)
+	}
+}
@@ -0,0 +1,73 @@
+package realtime
+
+import "sync"
+
+// Types of the events streamed to the clients
+const (
+	EventNotification = "notification"
+	EventUnreadCount  = "unread_count"
+)
+
+// Event tells a user that one of their notifications changed or that their unread count did.
+// Events only carry identifiers, the receiving instance loads the notification itself so the
+// payload stays within the NOTIFY size limit.
+type Event struct {
+	Type           string `json:"type"`
+	UserID         string `json:"user_id"`
+	NotificationID string `json:"notification_id,omitempty"`
+	UnreadCount    int64  `json:"unread_count"`
+}
+
+// subscriberBuffer is how many events a slow connection can lag behind before events are dropped
+const subscriberBuffer = 16
+
+// Hub delivers events to the connections of this instance
+type Hub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan Event]struct{}
+}
+
+// NewHub creates an empty hub
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[string]map[chan Event]struct{})}
+}
+
+// Subscribe registers a connection of the user, the returned function must be called when
+// the connection closes
+func (h *Hub) Subscribe(userID string) (<-chan Event, func()) {
+	events := make(chan Event, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[chan Event]struct{})
+	}
+	h.subscribers[userID][events] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers[userID], events)
+			if len(h.subscribers[userID]) == 0 {
+				delete(h.subscribers, userID)
+			}
+			h.mu.Unlock()
+		})
+	}
+	return events, unsubscribe
+}
+
+// Deliver sends the event to every connection of its user on this instance. A connection
+// whose buffer is full misses the event rather than blocking the others.
+func (h *Hub) Deliver(event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for events := range h.subscribers[event.UserID] {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
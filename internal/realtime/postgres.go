@@ -0,0 +1,92 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// Channel is the Postgres channel the events go through, every instance listens on it
+const Channel = "notification_events"
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// LocalHub holds the streaming connections of this instance
+var LocalHub = NewHub()
+
+// Setup starts listening for the events published by every instance, events are delivered
+// to LocalHub until ctx is cancelled
+func Setup(ctx context.Context, dsn string) {
+	go listen(ctx, dsn)
+}
+
+// Publish sends an event to the listeners of every instance, this one included
+func Publish(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	if err := database.DB.Exec("SELECT pg_notify(?, ?)", Channel, string(payload)).Error; err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// listen keeps a dedicated connection listening on Channel and reconnects when it drops. Events
+// published while reconnecting are lost, clients catch up through the unread count sent when
+// they reconnect or with the next event.
+func listen(ctx context.Context, dsn string) {
+	delay := minReconnectDelay
+	for {
+		connected, err := listenOnce(ctx, dsn)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			delay = minReconnectDelay
+		}
+		log.Printf("Realtime listener stopped, reconnecting in %s: %v", delay, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// listenOnce delivers events until the connection fails, it reports whether it got to listen
+func listenOnce(ctx context.Context, dsn string) (bool, error) {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+		return false, err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+
+		var event Event
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			log.Println("Error decoding realtime event:", err)
+			continue
+		}
+		LocalHub.Deliver(event)
+	}
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/handlers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func AdminRoutesSetup(app *fiber.App) {
+	admin := app.Group("/admin", handlers.RequireAdmin)
+
+	admin.Get("/push-outbox", handlers.GetPushOutboxHandler)
+	admin.Post("/push-outbox/:id/requeue", handlers.RequeuePushHandler)
+	admin.Get("/notifications/:id/delivery-stats", handlers.GetNotificationDeliveryStatsHandler)
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/handlers"
+	"github.com/gofiber/fiber/v2"
+)
+
+func CirclesRoutesSetup(app *fiber.App) {
+	app.Get("/circles", handlers.GetCirclesHandler)
+	app.Post("/circles", handlers.CreateCircleHandler)
+	app.Put("/circles/:id", handlers.UpdateCircleHandler)
+	app.Delete("/circles/:id", handlers.DeleteCircleHandler)
+
+	app.Get("/circles/:id/members", handlers.GetCircleMembersHandler)
+	app.Put("/circles/:id/members", handlers.AddCircleMembersHandler)
+	app.Delete("/circles/:id/members/:memberId", handlers.RemoveCircleMemberHandler)
+}
@@ -1,13 +1,35 @@
 package routes
 
 import (
+	"strings"
+
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/handlers"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/media"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
+	"github.com/Sajjad-iq/google_plus_react_native_go/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
+// postUploadBodyLimit leaves room for the images of a post and the other form fields
+const postUploadBodyLimit = services.MaxPostMedia*media.MaxImageBytes + 1<<20
+
+// IsPostUpload tells whether the request creates or edits a post, the only requests carrying
+// attachments. They are checked against postUploadBodyLimit instead of the default limit.
+func IsPostUpload(c *fiber.Ctx) bool {
+	switch c.Method() {
+	case fiber.MethodPost:
+		return c.Path() == "/create-post"
+	case fiber.MethodPut:
+		id, ok := strings.CutPrefix(c.Path(), "/posts/")
+		return ok && id != "" && !strings.Contains(id, "/")
+	}
+	return false
+}
+
 func PostsRoutesSetup(app *fiber.App) {
+	uploadLimit := middleware.BodyLimit(postUploadBodyLimit, nil)
 
-	app.Post("/create-post", func(c *fiber.Ctx) error {
+	app.Post("/create-post", uploadLimit, func(c *fiber.Ctx) error {
 		return handlers.CreatePost(c)
 	})
 
@@ -15,6 +37,9 @@ func PostsRoutesSetup(app *fiber.App) {
 		return handlers.GetPosts(c)
 	})
 
+	app.Get("/feed", handlers.GetHomeFeed)
+	app.Get("/hashtags/:tag/posts", handlers.GetHashtagPosts)
+
 	app.Get("/posts/post/:id", func(c *fiber.Ctx) error {
 		return handlers.GetPostByID(c)
 	})
@@ -24,9 +49,20 @@ func PostsRoutesSetup(app *fiber.App) {
 	})
 
 	app.Put("/posts/:id/like", handlers.LikePost)
+	app.Post("/posts/:id/reshare", handlers.ResharePost)
+	app.Put("/posts/:id", uploadLimit, handlers.EditPost)
+	app.Get("/posts/:id/revisions", handlers.GetPostRevisions)
 	app.Delete("/posts/:id", handlers.DeletePost)
+	app.Put("/posts/:id/mute", handlers.MutePostHandler)
+	app.Delete("/posts/:id/mute", handlers.UnmutePostHandler)
 
 	app.Delete("/posts/:id/comment", handlers.DeleteComment)
 	app.Put("/posts/:id/comment", handlers.CreateComment)
 	app.Get("/posts/comment/:id", handlers.FetchComments)
+
+	app.Put("/comments/:id", handlers.EditComment)
+	app.Get("/comments/:id/revisions", handlers.GetCommentRevisions)
+	app.Put("/comments/:id/reply", handlers.CreateReply)
+	app.Get("/comments/:id/replies", handlers.FetchReplies)
+	app.Put("/comments/:id/like", handlers.LikeComment)
 }
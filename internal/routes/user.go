@@ -7,9 +7,24 @@ import (
 
 func UsersRoutesSetup(app *fiber.App) {
 	app.Get("/user/:id", handlers.GetTheUser)
+	app.Put("/user/:id/follow", handlers.FollowUserHandler)
+	app.Delete("/user/:id/follow", handlers.UnfollowUserHandler)
+	app.Get("/user/:id/followers", handlers.GetFollowersHandler)
+	app.Get("/user/:id/following", handlers.GetFollowingHandler)
 	app.Get("/search", handlers.SearchUsers) // Use query parameter for name
 	app.Post("/test", func(c *fiber.Ctx) error { return handlers.OAuthUserLogin(c) })
 	app.Get("/notifications", handlers.FetchNotificationsHandler)
+	app.Get("/notifications/unread-count", handlers.GetUnreadNotificationsCountHandler)
+	app.Get("/notifications/stream", handlers.StreamNotificationsHandler)
+	app.Get("/notifications/preferences", handlers.GetNotificationPreferencesHandler)
+	app.Put("/notifications/preferences", handlers.UpdateNotificationPreferencesHandler)
+	app.Put("/notifications/read-all", handlers.MarkAllNotificationsAsReadHandler)
+	app.Put("/notifications/read", handlers.MarkNotificationsAsReadBulkHandler)
 	app.Put("/notifications/read/:id", handlers.MarkNotificationsAsReadHandler)
+	app.Delete("/notifications", handlers.DeleteAllNotificationsHandler)
+	app.Delete("/notifications/:id", handlers.DeleteNotificationHandler)
 	app.Put("/push-token", handlers.UpdatePushTokenHandler)
+	app.Get("/devices", handlers.GetDevicesHandler)
+	app.Post("/devices", handlers.RegisterDeviceHandler)
+	app.Delete("/devices/:id", handlers.UnregisterDeviceHandler)
 }
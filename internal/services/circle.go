@@ -0,0 +1,147 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrUnknownMember is returned when a circle member ID matches no user, the error names the ID
+var ErrUnknownMember = errors.New("unknown member")
+
+// ErrInvalidPostCircles is returned when the target circles of a post are missing or not the author's
+var ErrInvalidPostCircles = errors.New("invalid circles")
+
+// CreateCircleService creates a new circle owned by the user
+func CreateCircleService(ownerID, name, description string) (*models.Circle, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("circle name cannot be empty")
+	}
+
+	circle := &models.Circle{
+		ID:          uuid.New(),
+		UserID:      ownerID,
+		Name:        name,
+		Description: description,
+	}
+	if err := storage.CreateCircle(circle); err != nil {
+		return nil, err
+	}
+
+	return circle, nil
+}
+
+// FindOwnedCircle fetches a circle and makes sure it belongs to the user,
+// circles owned by someone else are reported as not found
+func FindOwnedCircle(circleID uuid.UUID, ownerID string) (*models.Circle, error) {
+	circle, err := storage.FindCircleByID(circleID)
+	if err != nil {
+		return nil, err
+	}
+	if circle.UserID != ownerID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return circle, nil
+}
+
+// UpdateCircleService renames a circle or changes its description
+func UpdateCircleService(circleID uuid.UUID, ownerID, name, description string) (*models.Circle, error) {
+	circle, err := FindOwnedCircle(circleID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if name = strings.TrimSpace(name); name != "" {
+		circle.Name = name
+	}
+	circle.Description = description
+
+	if err := storage.UpdateCircle(circle); err != nil {
+		return nil, fmt.Errorf("failed to update circle: %w", err)
+	}
+
+	return circle, nil
+}
+
+// DeleteCircleService deletes a circle owned by the user
+func DeleteCircleService(circleID uuid.UUID, ownerID string) error {
+	if _, err := FindOwnedCircle(circleID, ownerID); err != nil {
+		return err
+	}
+	return storage.DeleteCircle(circleID)
+}
+
+// AddCircleMembersService adds existing users to a circle owned by the user
+func AddCircleMembersService(circleID uuid.UUID, ownerID string, memberIDs []string) error {
+	if _, err := FindOwnedCircle(circleID, ownerID); err != nil {
+		return err
+	}
+
+	var members []models.CircleMember
+	for _, memberID := range memberIDs {
+		if memberID == "" || memberID == ownerID {
+			continue
+		}
+		if _, err := storage.FindUserByID(memberID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: %s", ErrUnknownMember, memberID)
+			}
+			return fmt.Errorf("failed to find user %s: %v", memberID, err)
+		}
+		members = append(members, models.CircleMember{CircleID: circleID, MemberID: memberID})
+	}
+
+	return storage.AddCircleMembers(members)
+}
+
+// RemoveCircleMemberService removes a user from a circle owned by the user
+func RemoveCircleMemberService(circleID uuid.UUID, ownerID, memberID string) error {
+	if _, err := FindOwnedCircle(circleID, ownerID); err != nil {
+		return err
+	}
+	return storage.RemoveCircleMember(circleID, memberID)
+}
+
+// ValidatePostCircles checks the target circles of a post. Circle posts need at least
+// one circle and every circle must belong to the author, other posts carry no circles.
+func ValidatePostCircles(post *models.Post, authorID string) error {
+	if post.ShareState != models.ShareStateCircles {
+		post.CircleIDs = nil
+		return nil
+	}
+
+	// Normalize the IDs and drop duplicates
+	seen := make(map[string]bool)
+	var circleIDs []string
+	for _, rawID := range post.CircleIDs {
+		circleID, err := uuid.Parse(strings.TrimSpace(rawID))
+		if err != nil {
+			return fmt.Errorf("%w: invalid circle id %s", ErrInvalidPostCircles, rawID)
+		}
+		if !seen[circleID.String()] {
+			seen[circleID.String()] = true
+			circleIDs = append(circleIDs, circleID.String())
+		}
+	}
+
+	if len(circleIDs) == 0 {
+		return fmt.Errorf("%w: circle_ids is required when sharing with circles", ErrInvalidPostCircles)
+	}
+
+	owned, err := storage.CountCirclesOwnedBy(authorID, circleIDs)
+	if err != nil {
+		return err
+	}
+	if owned != int64(len(circleIDs)) {
+		return fmt.Errorf("%w: you can only share with your own circles", ErrInvalidPostCircles)
+	}
+
+	post.CircleIDs = circleIDs
+	return nil
+}
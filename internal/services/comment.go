@@ -1,7 +1,10 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
@@ -11,6 +14,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrNotCommentAuthor is returned when someone other than the author tries to change a comment
+var ErrNotCommentAuthor = errors.New("only the author can edit this comment")
+
 // DeleteCommentService handles the logic of deleting a comment
 func DeleteCommentService(commentID uuid.UUID, userID string) error {
 	// Fetch the comment by its ID
@@ -24,29 +30,57 @@ func DeleteCommentService(commentID uuid.UUID, userID string) error {
 		return fmt.Errorf("unauthorized action: user is not the author of the comment")
 	}
 
+	// Delete the likes of the comment and of its replies
+	if err := storage.DeleteLikesByCommentID(commentID); err != nil {
+		return fmt.Errorf("failed to delete comment likes: %w", err)
+	}
+
+	// Delete the edit history of the comment and of its replies
+	if err := storage.DeleteCommentRevisions(commentID); err != nil {
+		return fmt.Errorf("failed to delete comment revisions: %w", err)
+	}
+
+	// Deleting a top level comment deletes its replies too
+	removed := 1
+	if comment.ParentID == nil {
+		repliesRemoved, err := storage.DeleteRepliesByParentID(commentID)
+		if err != nil {
+			return fmt.Errorf("failed to delete replies: %w", err)
+		}
+		removed += int(repliesRemoved)
+	}
+
 	// Call the storage function to delete the comment
 	if err := storage.DeleteComment(commentID); err != nil {
 		return fmt.Errorf("failed to delete comment: %w", err)
 	}
 
+	// Update the replies counter on the parent comment
+	if comment.ParentID != nil {
+		if err := storage.UpdateCommentRepliesCount(*comment.ParentID, -1); err != nil {
+			return err
+		}
+	}
+
 	// Update the comments counter on the post
-	if err := DecrementPostCommentsCounter(comment.PostID); err != nil {
+	if err := DecrementPostCommentsCounter(comment.PostID, removed); err != nil {
 		return fmt.Errorf("failed to update comments counter: %w", err)
 	}
 
 	return nil
 }
 
-// updateCommentsCounterOnDelete decrements the comments count for a given post ID
-func DecrementPostCommentsCounter(postID uuid.UUID) error {
+// DecrementPostCommentsCounter decrements the comments count for a given post ID by count
+func DecrementPostCommentsCounter(postID uuid.UUID, count int) error {
 	post, err := storage.GetPostByID(postID)
 	if err != nil {
 		return fmt.Errorf("failed to get post: %w", err)
 	}
 
-	if post.CommentsCount > 0 {
-		// Decrement the comments count
-		post.CommentsCount--
+	// Decrement the comments count
+	post.CommentsCount -= count
+	if post.CommentsCount < 0 {
+		post.CommentsCount = 0
 	}
 
 	// Save the updated post
@@ -70,14 +104,20 @@ func CreateCommentService(postID uuid.UUID, userID string, commentRequestBody re
 		return nil, err
 	}
 
-	// Fetch user by ID
-	post, err := storage.GetPostByID(postID)
+	// Fetch the post, posts the user can't see are reported as not found
+	post, err := storage.GetPostByIDForViewer(postID, userID)
 	if err != nil {
 		return nil, err
 	}
 
+	// Resolve every mentioned user, unknown users are dropped
+	mentions, err := ResolveMentions(commentedUser.ID, commentRequestBody.Content, commentRequestBody.MentionedUsers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve mentioned users: %w", err)
+	}
+
 	// Create a new comment
-	newComment, err := utils.CreateNewComment(post.ID, commentRequestBody, commentedUser)
+	newComment, err := utils.CreateNewComment(post.ID, commentRequestBody.Content, mentions, commentedUser)
 	if err != nil {
 		return nil, err
 	}
@@ -93,9 +133,7 @@ func CreateCommentService(postID uuid.UUID, userID string, commentRequestBody re
 	}
 
 	// Handle notifications
-	if err := handleCommentNotifications(commentRequestBody, commentedUser, *post); err != nil {
-		return nil, err
-	}
+	handleCommentNotifications(mentions, newComment.Content, commentedUser, *post)
 
 	return newComment, nil
 }
@@ -119,53 +157,293 @@ func IncrementPostCommentsCounter(postID uuid.UUID) (*models.Post, error) {
 	return post, nil
 }
 
-// FetchCommentsService retrieves comments for a given post ID with a limit
-func FetchCommentsService(postID uuid.UUID, limit int) ([]models.Comment, error) {
-	var comments []models.Comment
-
+// FetchCommentsService retrieves comments for a given post ID with a limit, starting after the cursor.
+// It returns gorm.ErrRecordNotFound when the viewer is not allowed to see the post.
+func FetchCommentsService(postID uuid.UUID, viewerID string, limit int, cursor *utils.Cursor) ([]models.Comment, error) {
 	// Validate that limit is greater than zero
 	if limit <= 0 {
 		return nil, fmt.Errorf("limit must be greater than zero")
 	}
 
-	// Fetch comments from the database using the postID, ordered by latest first, with a limit
-	if err := database.DB.Where("post_id = ?", postID).
-		Limit(limit).
-		Find(&comments).Error; err != nil {
+	// Make sure the viewer is allowed to see the post
+	if _, err := storage.GetPostByIDForViewer(postID, viewerID); err != nil {
+		return nil, err
+	}
+
+	// Fetch top level comments from the database using the postID, replies are loaded per comment
+	comments, err := storage.FetchComments(postID, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mark the comments the viewer has liked
+	if err := setCommentsYourLike(comments, viewerID); err != nil {
 		return nil, err
 	}
 
 	return comments, nil
 }
 
-func handleCommentNotifications(commentRequestBody requestModels.CreateCommentRequestBody, commentedUser *models.User, post models.Post) error {
-	var actionTypes []string
+// setCommentsYourLike sets 'YourLike' on each comment the viewer has liked
+func setCommentsYourLike(comments []models.Comment, viewerID string) error {
+	commentIDs := make([]uuid.UUID, len(comments))
+	for i := range comments {
+		commentIDs[i] = comments[i].ID
+	}
+
+	likedCommentIDs, err := storage.FindLikedCommentIDs(viewerID, commentIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range comments {
+		comments[i].YourLike = likedCommentIDs[comments[i].ID]
+	}
+	return nil
+}
+
+// CreateReplyService replies to a comment. Replies to a reply are attached to the same
+// top level comment so threads stay one level deep.
+func CreateReplyService(commentID uuid.UUID, userID string, commentRequestBody requestModels.CreateCommentRequestBody, lang string) (*models.Comment, error) {
+	// Validate comment content
+	if err := utils.ValidateCommentContent(commentRequestBody.Content); err != nil {
+		return nil, err
+	}
 
-	// Handle mention notifications
-	if len(commentRequestBody.MentionedUsers) > 0 && commentRequestBody.MentionedUsers[0].UserID != "" {
-		actionTypes = append(actionTypes, "mention")
-		notifyUser, err := storage.FindUserByID(commentRequestBody.MentionedUsers[0].UserID)
+	// Fetch the comment being replied to and the top level comment of its thread
+	repliedComment, err := storage.FindCommentByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	threadComment := repliedComment
+	if repliedComment.ParentID != nil {
+		threadComment, err = storage.FindCommentByID(*repliedComment.ParentID)
 		if err != nil {
-			return fmt.Errorf("failed to create or update notification: %w", err)
+			return nil, err
 		}
+	}
 
-		_, err = CreateOrUpdateNotification(notifyUser, commentedUser.ID, actionTypes, post.ID, commentRequestBody.Content)
-		if err != nil {
-			return fmt.Errorf("failed to create or update notification: %w", err)
+	// Fetch user by ID
+	commentedUser, err := storage.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the post, posts the user can't see are reported as not found
+	post, err := storage.GetPostByIDForViewer(threadComment.PostID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve every mentioned user, unknown users are dropped
+	mentions, err := ResolveMentions(commentedUser.ID, commentRequestBody.Content, commentRequestBody.MentionedUsers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve mentioned users: %w", err)
+	}
+
+	// Create a new reply
+	newReply, err := utils.CreateNewComment(post.ID, commentRequestBody.Content, mentions, commentedUser)
+	if err != nil {
+		return nil, err
+	}
+	newReply.ParentID = &threadComment.ID
+
+	// Save the reply
+	if err := storage.SaveComment(newReply); err != nil {
+		return nil, fmt.Errorf("failed to save reply: %w", err)
+	}
+
+	// Increment the replies counter of the thread and the post comments counter
+	if err := storage.UpdateCommentRepliesCount(threadComment.ID, 1); err != nil {
+		return nil, err
+	}
+	if _, err := IncrementPostCommentsCounter(post.ID); err != nil {
+		return nil, fmt.Errorf("failed to update comments counter: %w", err)
+	}
+
+	// Handle notifications
+	handleReplyNotifications(mentions, newReply.Content, commentedUser, *post, *repliedComment)
+
+	return newReply, nil
+}
+
+// FetchRepliesService retrieves the replies of a comment with a limit, starting after the cursor.
+// It returns gorm.ErrRecordNotFound when the viewer is not allowed to see the post.
+func FetchRepliesService(commentID uuid.UUID, viewerID string, limit int, cursor *utils.Cursor) ([]models.Comment, error) {
+	// Validate that limit is greater than zero
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be greater than zero")
+	}
+
+	comment, err := storage.FindCommentByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure the viewer is allowed to see the post
+	if _, err := storage.GetPostByIDForViewer(comment.PostID, viewerID); err != nil {
+		return nil, err
+	}
+
+	replies, err := storage.FetchReplies(comment.ID, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mark the replies the viewer has liked
+	if err := setCommentsYourLike(replies, viewerID); err != nil {
+		return nil, err
+	}
+
+	return replies, nil
+}
+
+// EditCommentService updates the content of a comment made by the user. The previous version is
+// kept as a revision and users mentioned for the first time are notified.
+func EditCommentService(commentID uuid.UUID, userID string, requestBody requestModels.EditCommentRequestBody) (*models.Comment, error) {
+	// Validate comment content
+	if err := utils.ValidateCommentContent(requestBody.Content); err != nil {
+		return nil, err
+	}
+
+	comment, err := storage.FindCommentByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserID != userID {
+		return nil, ErrNotCommentAuthor
+	}
+
+	// Comments on posts the user can't see anymore are reported as not found
+	post, err := storage.GetPostByIDForViewer(comment.PostID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the structured mentions of the previous version unless the client sends new ones
+	requested := []models.MentionedUser(comment.MentionedUsers)
+	if requestBody.MentionedUsers != nil {
+		requested = requestBody.MentionedUsers
+	}
+	mentions, err := ResolveMentions(userID, requestBody.Content, requested)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve mentioned users: %w", err)
+	}
+
+	// Snapshot the current version before changing anything
+	revision := &models.CommentRevision{
+		CommentID:      comment.ID,
+		Content:        comment.Content,
+		MentionedUsers: comment.MentionedUsers,
+	}
+	previousMentions := comment.MentionedUsers
+
+	editedAt := time.Now()
+	comment.Content = requestBody.Content
+	comment.MentionedUsers = mentions
+	comment.Edited = true
+	comment.EditedAt = &editedAt
+
+	if err := storage.UpdateCommentWithRevision(comment, revision); err != nil {
+		return nil, err
+	}
+
+	// Users who were already mentioned have been notified when the comment was created
+	notified := map[string]bool{userID: true}
+	for _, mention := range previousMentions {
+		notified[mention.UserID] = true
+	}
+	notifyCommentMentions(mentions, notified, userID, post.ID, comment.Content)
+
+	return comment, nil
+}
+
+// GetCommentRevisionsService returns the edit history of a comment, only its author can see it
+func GetCommentRevisionsService(commentID uuid.UUID, userID string) ([]models.CommentRevision, error) {
+	comment, err := storage.FindCommentByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserID != userID {
+		return nil, ErrNotCommentAuthor
+	}
+
+	return storage.GetCommentRevisions(commentID)
+}
+
+// handleReplyNotifications notifies the author of the replied comment and every mentioned user once.
+// The reply is already saved so failures are only logged.
+func handleReplyNotifications(mentions models.MentionedUserArray, content string, commentedUser *models.User, post models.Post, repliedComment models.Comment) {
+	notified := map[string]bool{commentedUser.ID: true}
+
+	// Notify the author of the replied comment, a single notification also covers being mentioned
+	if !notified[repliedComment.UserID] {
+		actionTypes := []string{"reply"}
+		if isMentioned(mentions, repliedComment.UserID) {
+			actionTypes = append(actionTypes, "mention")
 		}
-	} else if post.AuthorID != commentedUser.ID {
-		// Handle comment notifications to post author
-		notifyUser, err := storage.FindUserByID(post.AuthorID)
-		if err != nil {
-			return fmt.Errorf("failed to create or update notification: %w", err)
+		notifyCommentParticipant(repliedComment.UserID, commentedUser.ID, actionTypes, post.ID, content)
+		notified[repliedComment.UserID] = true
+	}
+
+	notifyCommentMentions(mentions, notified, commentedUser.ID, post.ID, content)
+}
+
+// handleCommentNotifications notifies the post author and every mentioned user once.
+// The comment is already saved so failures are only logged.
+func handleCommentNotifications(mentions models.MentionedUserArray, content string, commentedUser *models.User, post models.Post) {
+	notified := map[string]bool{commentedUser.ID: true}
+
+	// The post author always hears about comments, a single notification also covers being mentioned
+	if !notified[post.AuthorID] {
+		actionTypes := []string{"comment"}
+		if isMentioned(mentions, post.AuthorID) {
+			actionTypes = append(actionTypes, "mention")
 		}
+		notifyCommentParticipant(post.AuthorID, commentedUser.ID, actionTypes, post.ID, content)
+		notified[post.AuthorID] = true
+	}
 
-		actionTypes = append(actionTypes, "comment")
-		_, err = CreateOrUpdateNotification(notifyUser, commentedUser.ID, actionTypes, post.ID, commentRequestBody.Content)
-		if err != nil {
-			return fmt.Errorf("failed to create or update notification: %w", err)
+	notifyCommentMentions(mentions, notified, commentedUser.ID, post.ID, content)
+}
+
+// notifyCommentMentions sends a "mention" notification to each mentioned user who was not notified
+// yet and who can see the post
+func notifyCommentMentions(mentions models.MentionedUserArray, notified map[string]bool, actorID string, postID uuid.UUID, content string) {
+	for _, mention := range mentions {
+		if notified[mention.UserID] {
+			continue
+		}
+		notified[mention.UserID] = true
+
+		// Mentioning someone must not leak a post they are not allowed to see
+		canView, err := storage.CanViewPost(postID, mention.UserID)
+		if err != nil || !canView {
+			continue
 		}
+
+		notifyCommentParticipant(mention.UserID, actorID, []string{"mention"}, postID, content)
 	}
+}
 
-	return nil
+// notifyCommentParticipant creates or updates the notification of a single user, failures are logged
+func notifyCommentParticipant(userID string, actorID string, actionTypes []string, postID uuid.UUID, content string) {
+	notifyUser, err := storage.FindUserByID(userID)
+	if err != nil {
+		log.Println("Error finding user to notify:", err)
+		return
+	}
+
+	if _, err := CreateOrUpdateNotification(notifyUser, actorID, actionTypes, postID, content); err != nil {
+		log.Println("Error creating comment notification:", err)
+	}
+}
+
+func isMentioned(mentions models.MentionedUserArray, userID string) bool {
+	for _, mention := range mentions {
+		if mention.UserID == userID {
+			return true
+		}
+	}
+	return false
 }
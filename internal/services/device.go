@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models/requestModels"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/push"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxUserDevices caps the devices of a user, the least recently seen ones are dropped
+	MaxUserDevices = 20
+	// DeviceActiveWindow is how long a device gets pushes after it was last registered
+	DeviceActiveWindow = 90 * 24 * time.Hour
+
+	maxPushTokenLength  = 512
+	maxDeviceInfoLength = 64
+)
+
+var (
+	ErrInvalidPushToken = errors.New("invalid push token")
+	ErrInvalidPlatform  = errors.New("platform must be ios, android or web")
+)
+
+// RegisterDeviceService registers a device of the user or refreshes it, apps call it on every
+// launch so the last seen time tells which devices are still in use
+func RegisterDeviceService(userID string, request requestModels.RegisterDeviceRequestBody) (*models.UserDevice, error) {
+	token := strings.TrimSpace(request.Token)
+	if token == "" || len(token) > maxPushTokenLength {
+		return nil, ErrInvalidPushToken
+	}
+
+	platform := strings.ToLower(strings.TrimSpace(request.Platform))
+	switch platform {
+	case models.PlatformIOS, models.PlatformAndroid, models.PlatformWeb:
+	case "":
+		platform = guessPlatform(token)
+	default:
+		return nil, ErrInvalidPlatform
+	}
+
+	device := &models.UserDevice{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Token:      token,
+		Platform:   platform,
+		AppVersion: truncateRunes(strings.TrimSpace(request.AppVersion), maxDeviceInfoLength),
+		Locale:     truncateRunes(strings.TrimSpace(request.Locale), maxDeviceInfoLength),
+		LastSeenAt: time.Now(),
+	}
+	if err := storage.SaveUserDevice(device); err != nil {
+		return nil, err
+	}
+
+	if err := storage.PruneUserDevices(userID, MaxUserDevices); err != nil {
+		return nil, fmt.Errorf("failed to prune devices: %w", err)
+	}
+	return device, nil
+}
+
+// guessPlatform tells the platform from the token type, Expo tokens don't reveal it
+func guessPlatform(token string) string {
+	switch push.DetectTokenType(token) {
+	case push.TokenTypeAPNs:
+		return models.PlatformIOS
+	case push.TokenTypeFCM:
+		return models.PlatformAndroid
+	default:
+		return ""
+	}
+}
+
+// GetUserDevicesService lists the devices of the user
+func GetUserDevicesService(userID string) ([]models.UserDevice, error) {
+	return storage.FindUserDevices(userID)
+}
+
+// UnregisterDeviceService removes a device of the user, it returns gorm.ErrRecordNotFound when the
+// device doesn't exist or belongs to someone else
+func UnregisterDeviceService(deviceID uuid.UUID, userID string) error {
+	return storage.DeleteUserDevice(deviceID, userID)
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
+)
+
+// DefaultFeedRanking is used for every weight that is not set in the environment
+var DefaultFeedRanking = storage.FeedRanking{
+	RecencyWeight:        3,
+	RecencyHalfLifeHours: 24,
+	EngagementWeight:     1,
+	AffinityWeight:       1.5,
+	FollowWeight:         2,
+	MaxAgeHours:          24 * 7,
+}
+
+// LoadFeedRanking reads the home feed weights from the environment, falling back to DefaultFeedRanking
+func LoadFeedRanking() storage.FeedRanking {
+	ranking := DefaultFeedRanking
+	ranking.RecencyWeight = feedWeightFromEnv("FEED_RECENCY_WEIGHT", ranking.RecencyWeight)
+	ranking.RecencyHalfLifeHours = feedWeightFromEnv("FEED_RECENCY_HALF_LIFE_HOURS", ranking.RecencyHalfLifeHours)
+	ranking.EngagementWeight = feedWeightFromEnv("FEED_ENGAGEMENT_WEIGHT", ranking.EngagementWeight)
+	ranking.AffinityWeight = feedWeightFromEnv("FEED_AFFINITY_WEIGHT", ranking.AffinityWeight)
+	ranking.FollowWeight = feedWeightFromEnv("FEED_FOLLOW_WEIGHT", ranking.FollowWeight)
+	ranking.MaxAgeHours = feedWeightFromEnv("FEED_MAX_AGE_HOURS", ranking.MaxAgeHours)
+
+	// A zero half life or max age would empty the feed
+	if ranking.RecencyHalfLifeHours <= 0 {
+		ranking.RecencyHalfLifeHours = DefaultFeedRanking.RecencyHalfLifeHours
+	}
+	if ranking.MaxAgeHours <= 0 {
+		ranking.MaxAgeHours = DefaultFeedRanking.MaxAgeHours
+	}
+
+	return ranking
+}
+
+func feedWeightFromEnv(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	weight, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Invalid %s value %q, using %v", key, value, fallback)
+		return fallback
+	}
+	return weight
+}
+
+// feedSnapshotRetention is how long a feed session can be paged through before it ends
+const feedSnapshotRetention = 24 * time.Hour
+
+// HomeFeedService retrieves a page of the viewer's ranked home feed. The first page scores the
+// feed as of now and keeps it as a snapshot, the following pages are read from the snapshot whose
+// time is stored in the cursor.
+func HomeFeedService(viewerID string, limit int, cursor *utils.Cursor) ([]models.Post, time.Time, error) {
+	if cursor != nil {
+		posts, err := storage.GetHomeFeed(viewerID, cursor.Time, limit, cursor)
+		return posts, cursor.Time, err
+	}
+
+	// The database keeps microseconds, the snapshot time must match it exactly on the next pages
+	asOf := time.Now().Truncate(time.Microsecond)
+
+	// Snapshots of sessions that ended are not needed anymore, a failed cleanup is retried next time
+	if err := storage.DeleteFeedSnapshotsBefore(asOf.Add(-feedSnapshotRetention)); err != nil {
+		log.Println("Error deleting old feed snapshots:", err)
+	}
+	if err := storage.CreateFeedSnapshot(viewerID, LoadFeedRanking(), asOf); err != nil {
+		return nil, asOf, err
+	}
+
+	posts, err := storage.GetHomeFeed(viewerID, asOf, limit, nil)
+	if err != nil {
+		return nil, asOf, err
+	}
+
+	return posts, asOf, nil
+}
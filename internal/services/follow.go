@@ -0,0 +1,54 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"gorm.io/gorm"
+)
+
+// FollowUser makes followerID follow followingID, it is a no-op if the follow already exists
+func FollowUser(followerID, followingID string) error {
+	if followerID == followingID {
+		return fmt.Errorf("you cannot follow yourself")
+	}
+
+	// Make sure the user being followed exists
+	if _, err := storage.FindUserByID(followingID); err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	// Skip if the user is already following
+	_, err := storage.FindFollow(followerID, followingID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to check follow state: %w", err)
+	}
+
+	newFollow := models.Follow{
+		FollowerID:  followerID,
+		FollowingID: followingID,
+	}
+	return storage.CreateFollow(&newFollow)
+}
+
+// UnfollowUser removes the follow relationship between two users
+func UnfollowUser(followerID, followingID string) error {
+	return storage.DeleteFollow(followerID, followingID)
+}
+
+// IsFollowing reports whether followerID follows followingID
+func IsFollowing(followerID, followingID string) (bool, error) {
+	_, err := storage.FindFollow(followerID, followingID)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return false, err
+}
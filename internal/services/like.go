@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
@@ -17,13 +18,13 @@ func ToggleLike(post *models.Post, userID string, lang string) (bool, error) {
 			return false, fmt.Errorf("failed to remove like: %w", err)
 		}
 
-		// Decrement the like count
-		post.LikesCount--
-
-		// Update the post in the database
-		if err := storage.UpdatePost(post); err != nil {
+		// Decrement the like count in place so the rest of the post isn't overwritten
+		if err := storage.UpdatePostLikesCount(post.ID, -1); err != nil {
 			return false, fmt.Errorf("failed to update post after removing like: %w", err)
 		}
+		if post.LikesCount > 0 {
+			post.LikesCount--
+		}
 
 		return false, nil // Returning false to indicate the post is now unliked
 	}
@@ -37,13 +38,11 @@ func ToggleLike(post *models.Post, userID string, lang string) (bool, error) {
 		return false, fmt.Errorf("failed to add like: %w", err)
 	}
 
-	// Increment the like count
-	post.LikesCount++
-
-	// Update the post in the database
-	if err := storage.UpdatePost(post); err != nil {
+	// Increment the like count in place
+	if err := storage.UpdatePostLikesCount(post.ID, 1); err != nil {
 		return false, fmt.Errorf("failed to update post after adding like: %w", err)
 	}
+	post.LikesCount++
 
 	notifyUser, err := storage.FindUserByID(post.AuthorID)
 	if err != nil {
@@ -54,11 +53,66 @@ func ToggleLike(post *models.Post, userID string, lang string) (bool, error) {
 	if notifyUser.ID != userID {
 		// Create or update a notification for the post like
 		actionTypes := []string{"like"} // Define the action type as an array of strings
-		_, err = CreateOrUpdateNotification(notifyUser, userID, actionTypes, post.ID, post.Body)
-		if err != nil {
-			return false, fmt.Errorf("failed to create or update notification: %w", err)
+		// The like is already saved, a failed notification is only logged
+		if _, err := CreateOrUpdateNotification(notifyUser, userID, actionTypes, post.ID, post.Body); err != nil {
+			log.Println("Error creating like notification:", err)
 		}
 	}
 
 	return true, nil // Returning true to indicate the post is now liked
 }
+
+// ToggleCommentLike handles the logic for liking or unliking a comment and manages notifications
+func ToggleCommentLike(comment *models.Comment, userID string, lang string) (bool, error) {
+	// Check if the user has already liked the comment
+	existingLike, err := storage.FindLikeByUserAndComment(comment.ID, userID)
+	if err == nil && existingLike != nil {
+		// User has already liked the comment, remove like
+		if err := storage.DeleteLike(existingLike); err != nil {
+			return false, fmt.Errorf("failed to remove like: %w", err)
+		}
+
+		// Decrement the like count in place so concurrent toggles don't overwrite each other
+		if err := storage.UpdateCommentLikesCount(comment.ID, -1); err != nil {
+			return false, fmt.Errorf("failed to update comment after removing like: %w", err)
+		}
+		if comment.LikesCount > 0 {
+			comment.LikesCount--
+		}
+
+		return false, nil // Returning false to indicate the comment is now unliked
+	}
+
+	// If the user hasn't liked the comment yet, add a new like
+	newLike := models.Like{
+		UserID:    userID,
+		PostID:    comment.PostID,
+		CommentID: &comment.ID,
+	}
+	if err := storage.CreateLike(&newLike); err != nil {
+		return false, fmt.Errorf("failed to add like: %w", err)
+	}
+
+	// Increment the like count in place
+	if err := storage.UpdateCommentLikesCount(comment.ID, 1); err != nil {
+		return false, fmt.Errorf("failed to update comment after adding like: %w", err)
+	}
+	comment.LikesCount++
+
+	notifyUser, err := storage.FindUserByID(comment.UserID)
+	if err != nil {
+		// If an error occurs (e.g., user not found), the like is still saved
+		return true, nil
+	}
+
+	if notifyUser.ID != userID {
+		// Create or update a notification for the comment like, it points to the post of the comment
+		actionTypes := []string{"comment_like"}
+		// The like is already saved, a failed notification is only logged
+		if _, err := CreateOrUpdateNotification(notifyUser, userID, actionTypes, comment.PostID, comment.Content); err != nil {
+			log.Println("Error creating like notification:", err)
+		}
+	}
+
+	return true, nil // Returning true to indicate the comment is now liked
+}
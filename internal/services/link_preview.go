@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/linkpreview"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
+)
+
+// linkPreviewTimeout bounds the background fetch of a link preview
+const linkPreviewTimeout = 5 * time.Second
+
+// LinkPreviewFetcher builds the link previews of posts, tests can replace it with a fetcher
+// allowed to reach a local stand-in server
+var LinkPreviewFetcher linkpreview.Fetcher = linkpreview.NewHTTPFetcher(linkpreview.Options{})
+
+// AttachLinkPreview prepares the preview of the first link of the post body before the post is
+// saved. The current preview is kept when the link didn't change, otherwise it is cleared and the
+// link to pass to FetchLinkPreview once the post is saved is returned.
+func AttachLinkPreview(post *models.Post) string {
+	link := utils.ExtractFirstLink(post.Body)
+	if link != "" && post.LinkPreview != nil && post.LinkPreview.URL == link {
+		return ""
+	}
+	post.LinkPreview = nil
+	return link
+}
+
+// FetchLinkPreview fetches the preview of a saved post in the background so the request doesn't
+// wait for a slow site. A failed fetch only leaves the post without preview, and the preview is
+// dropped when the body was edited in the meantime.
+func FetchLinkPreview(post *models.Post, link string) {
+	if link == "" {
+		return
+	}
+
+	postID, body := post.ID, post.Body
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), linkPreviewTimeout)
+		defer cancel()
+
+		preview, err := LinkPreviewFetcher.Fetch(ctx, link)
+		if err != nil {
+			log.Println("Error fetching link preview:", err)
+			return
+		}
+		if preview == nil {
+			return
+		}
+		if err := storage.UpdatePostLinkPreview(postID, body, preview); err != nil {
+			log.Println("Error saving link preview:", err)
+		}
+	}()
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"log"
+	"strings"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
+)
+
+// maxMentions caps how many users a single post or comment can mention
+const maxMentions = 50
+
+// ResolveMentions builds the validated list of users mentioned in a text. Mentions come from
+// the structured list sent by the client and from the @handles found in the text. Unknown
+// users, ambiguous handles and the author are dropped.
+func ResolveMentions(authorID string, text string, requested []models.MentionedUser) (models.MentionedUserArray, error) {
+	// Structured mentions, only numeric IDs can be real user IDs
+	var requestedIDs []string
+	for _, mention := range requested {
+		userID := strings.TrimSpace(mention.UserID)
+		if userID != "" && isNumericID(userID) {
+			requestedIDs = append(requestedIDs, userID)
+		}
+	}
+
+	users, err := storage.FindUsersByIDs(requestedIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mentions written in the text, a handle shared by several users is ambiguous and skipped
+	handles := utils.ExtractMentionHandles(text)
+	handleUsers, err := storage.FindUsersByMentionHandles(handles)
+	if err != nil {
+		return nil, err
+	}
+	usersByHandle := make(map[string][]models.User)
+	for _, user := range handleUsers {
+		handle := utils.NormalizeMentionHandle(user.Username)
+		usersByHandle[handle] = append(usersByHandle[handle], user)
+	}
+	for _, handle := range handles {
+		if matches := usersByHandle[handle]; len(matches) == 1 {
+			users = append(users, matches[0])
+		}
+	}
+
+	// Keep each user once, in order, and never the author
+	seen := map[string]bool{authorID: true}
+	mentions := models.MentionedUserArray{}
+	for _, user := range users {
+		if seen[user.ID] || len(mentions) >= maxMentions {
+			continue
+		}
+		seen[user.ID] = true
+		mentions = append(mentions, models.MentionedUser{UserID: user.ID, UserName: user.Username})
+	}
+
+	return mentions, nil
+}
+
+// NotifyPostMentions sends a "mention" notification to each mentioned user who can see the post.
+// The post is already saved so failures are only logged.
+func NotifyPostMentions(post *models.Post, mentions models.MentionedUserArray) {
+	for _, mention := range mentions {
+		// Mentioning someone must not leak a post they are not allowed to see
+		canView, err := storage.CanViewPost(post.ID, mention.UserID)
+		if err != nil || !canView {
+			continue
+		}
+
+		notifyUser, err := storage.FindUserByID(mention.UserID)
+		if err != nil {
+			continue
+		}
+
+		if _, err := CreateOrUpdateNotification(notifyUser, post.AuthorID, []string{"mention"}, post.ID, post.Body); err != nil {
+			log.Println("Error creating mention notification:", err)
+		}
+	}
+}
+
+func isNumericID(id string) bool {
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return id != ""
+}
@@ -11,13 +11,30 @@ import (
 	"github.com/google/uuid"
 )
 
-// CreateOrUpdateNotification handles updating or creating a notification
+// CreateOrUpdateNotification handles updating or creating a notification. The push is queued in
+// the same transaction and delivered by the push worker, so a slow or failing push provider
+// never fails the action that triggered the notification. The notification preferences of the
+// user are respected, nil is returned when no in-app notification is kept.
 func CreateOrUpdateNotification(notifyUser *models.User, actorID string, actionTypes []string, referenceID uuid.UUID, referenceContent string) (*models.Notification, error) {
-	// Check for an existing notification
-	existingNotification, err := storage.FindNotificationByUserActionAndReference(notifyUser.ID, referenceID)
+	// Nothing is sent about posts the user muted
+	muted, err := storage.IsPostMuted(notifyUser.ID, referenceID)
 	if err != nil {
-		log.Println("Error checking for existing notification:", err)
-		return nil, fmt.Errorf("failed to check for existing notification: %w", err)
+		log.Println("Error checking muted post:", err)
+		return nil, err
+	}
+	if muted {
+		return nil, nil
+	}
+
+	// Keep the action types the user wants to hear about in each channel
+	preferences, err := storage.FindNotificationPreferences(notifyUser.ID)
+	if err != nil {
+		log.Println("Error fetching notification preferences:", err)
+		return nil, err
+	}
+	inAppTypes, pushTypes := channelActionTypes(preferences, actionTypes)
+	if len(inAppTypes) == 0 && len(pushTypes) == 0 {
+		return nil, nil
 	}
 
 	// Fetch or create the actor
@@ -27,31 +44,58 @@ func CreateOrUpdateNotification(notifyUser *models.User, actorID string, actionT
 		return nil, err
 	}
 
+	// Only push the notification when the in-app ones are off, it isn't kept in the list
+	if len(inAppTypes) == 0 {
+		notification := createNewNotification(notifyUser.ID, actor, pushTypes, referenceID, referenceContent)
+		pushes, err := newPushes(notifyUser, notification, pushTypes[len(pushTypes)-1])
+		if err != nil {
+			log.Println("Error building push notifications:", err)
+			return nil, fmt.Errorf("failed to build push notifications: %w", err)
+		}
+		if err := storage.QueuePushes(notification.ID, pushes); err != nil {
+			log.Println("Error queueing push notifications:", err)
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// Check for an existing notification
+	existingNotification, err := storage.FindNotificationByUserActionAndReference(notifyUser.ID, referenceID)
+	if err != nil {
+		log.Println("Error checking for existing notification:", err)
+		return nil, fmt.Errorf("failed to check for existing notification: %w", err)
+	}
+
 	var notification *models.Notification
 
 	if existingNotification != nil {
 		// Update the existing notification
-		if err := updateExistingNotification(existingNotification, actor, actionTypes, referenceContent); err != nil {
-			log.Println("Error updating existing notification:", err)
-			return nil, err
-		}
+		updateExistingNotification(existingNotification, actor, inAppTypes, referenceContent)
 		notification = existingNotification
 	} else {
 		// Create a new notification
-		var err error
-		notification, err = createNewNotification(notifyUser.ID, actor, actionTypes, referenceID, referenceContent)
+		notification = createNewNotification(notifyUser.ID, actor, inAppTypes, referenceID, referenceContent)
+	}
+
+	// Queue a push for each device of the user when the push of this action type is on
+	var pushes []models.PushOutbox
+	if len(pushTypes) > 0 {
+		pushes, err = newPushes(notifyUser, notification, pushTypes[len(pushTypes)-1])
 		if err != nil {
-			log.Println("Error creating new notification:", err)
-			return nil, err
+			log.Println("Error building push notifications:", err)
+			return nil, fmt.Errorf("failed to build push notifications: %w", err)
 		}
 	}
 
-	// Send notification using Expo Push Notification API
-	if err := utils.SendPushNotification(notifyUser, notification); err != nil {
-		log.Println("Error sending push notification:", err)
-		return nil, err
+	// Save the notification together with its pushes
+	if err := storage.SaveNotificationWithPushes(notification, pushes); err != nil {
+		log.Println("Error saving notification:", err)
+		return nil, fmt.Errorf("failed to save notification: %w", err)
 	}
 
+	// Stream the notification to the user's open connections
+	publishNotification(notification)
+
 	return notification, nil
 }
 
@@ -72,7 +116,7 @@ func CreateActor(actorID string) (models.Actor, error) {
 }
 
 // UpdateExistingNotification updates an existing notification with the new actor
-func updateExistingNotification(notification *models.Notification, actor models.Actor, newActionTypes []string, ReferenceContent string) error {
+func updateExistingNotification(notification *models.Notification, actor models.Actor, newActionTypes []string, ReferenceContent string) {
 	// Check if the actor is already part of the notification
 	actorExists := false
 	for i, existingActor := range notification.Actors {
@@ -109,18 +153,11 @@ func updateExistingNotification(notification *models.Notification, actor models.
 	notification.UpdatedAt = time.Now()
 	notification.ReferenceContent = ReferenceContent
 	notification.IsRead = false
-
-	// Save the updated notification
-	if err := storage.SaveNotification(notification); err != nil {
-		return fmt.Errorf("failed to update notification: %w", err)
-	}
-
-	return nil
 }
 
-// CreateNewNotification creates a new notification entry
-func createNewNotification(userID string, actor models.Actor, actionTypes []string, referenceID uuid.UUID, ReferenceContent string) (*models.Notification, error) {
-	newNotification := models.Notification{
+// CreateNewNotification builds a new notification entry
+func createNewNotification(userID string, actor models.Actor, actionTypes []string, referenceID uuid.UUID, ReferenceContent string) *models.Notification {
+	return &models.Notification{
 		ID:                  uuid.New(),
 		UserID:              userID,
 		Actors:              []models.Actor{actor}, // Adding the actor to the Actors array
@@ -132,28 +169,92 @@ func createNewNotification(userID string, actor models.Actor, actionTypes []stri
 		CreatedAt:           time.Now(),
 		UpdatedAt:           time.Now(),
 	}
+}
 
-	// Save the new notification
-	if err := storage.SaveNotification(&newNotification); err != nil {
-		log.Println("Error creating new notification:", err)
-		return nil, fmt.Errorf("failed to create notification: %w", err)
-	}
+// MaxBulkNotifications caps how many notifications a single bulk request can change
+const MaxBulkNotifications = 100
 
-	return &newNotification, nil
-}
+// ErrTooManyNotifications is returned when a bulk request lists more than MaxBulkNotifications IDs
+var ErrTooManyNotifications = fmt.Errorf("at most %d notifications can be changed at once", MaxBulkNotifications)
 
-// DeleteNotificationService deletes a notification by its ID
-func DeleteNotificationService(notificationID uuid.UUID) error {
-	if err := storage.DeleteNotification(notificationID); err != nil {
+// DeleteNotificationService deletes a notification of the user, it returns gorm.ErrRecordNotFound
+// when the notification doesn't exist or belongs to someone else
+func DeleteNotificationService(notificationID uuid.UUID, userID string) error {
+	if err := storage.DeleteNotification(notificationID, userID); err != nil {
 		log.Println("Error deleting notification:", err)
 		return fmt.Errorf("failed to delete notification: %w", err)
 	}
+	publishUnreadCount(userID)
 	return nil
 }
 
-// FetchUserNotificationsService fetches notifications for a user
-func FetchUserNotificationsService(userID string, limit int, lang string) ([]models.Notification, error) {
-	notifications, err := storage.FetchNotificationsByUserID(userID, limit)
+// DeleteAllNotificationsService deletes every notification of the user
+func DeleteAllNotificationsService(userID string) (int64, error) {
+	deleted, err := storage.DeleteAllNotifications(userID)
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		publishUnreadCount(userID)
+	}
+	return deleted, nil
+}
+
+// MarkNotificationAsReadService marks a notification of the user as read, it returns
+// gorm.ErrRecordNotFound when the notification doesn't exist or belongs to someone else
+func MarkNotificationAsReadService(notificationID uuid.UUID, userID string) error {
+	if err := storage.MarkNotificationAsRead(notificationID, userID); err != nil {
+		return err
+	}
+	publishUnreadCount(userID)
+	return nil
+}
+
+// MarkNotificationsAsReadService marks several notifications of the user as read
+func MarkNotificationsAsReadService(userID string, notificationIDs []uuid.UUID) (int64, error) {
+	if len(notificationIDs) > MaxBulkNotifications {
+		return 0, ErrTooManyNotifications
+	}
+	updated, err := storage.MarkNotificationsAsRead(userID, notificationIDs)
+	if err != nil {
+		return 0, err
+	}
+	if updated > 0 {
+		publishUnreadCount(userID)
+	}
+	return updated, nil
+}
+
+// MarkAllNotificationsAsReadService marks every notification of the user as read
+func MarkAllNotificationsAsReadService(userID string) (int64, error) {
+	updated, err := storage.MarkAllNotificationsAsRead(userID)
+	if err != nil {
+		return 0, err
+	}
+	if updated > 0 {
+		publishUnreadCount(userID)
+	}
+	return updated, nil
+}
+
+// FindUserNotificationService loads a notification of the user with its message in the given language
+func FindUserNotificationService(notificationID uuid.UUID, userID string, lang string) (*models.Notification, error) {
+	notification, err := storage.FindNotificationByID(notificationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	notification.NotificationContent = utils.CreateNotificationMessage(*notification, lang)
+	return notification, nil
+}
+
+// CountUnreadNotificationsService counts the unread notifications of the user
+func CountUnreadNotificationsService(userID string) (int64, error) {
+	return storage.CountUnreadNotifications(userID)
+}
+
+// FetchUserNotificationsService fetches notifications for a user, starting after the cursor
+func FetchUserNotificationsService(userID string, limit int, cursor *utils.Cursor, lang string) ([]models.Notification, error) {
+	notifications, err := storage.FetchNotificationsByUserID(userID, limit, cursor)
 	if err != nil {
 		log.Println("Error fetching user notifications:", err)
 		return nil, fmt.Errorf("failed to fetch notifications: %w", err)
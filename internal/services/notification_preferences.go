@@ -0,0 +1,178 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models/requestModels"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const quietHoursLayout = "15:04"
+
+var (
+	ErrInvalidActionType = errors.New("invalid notification action type")
+	ErrInvalidQuietHours = errors.New("quiet hours need a start and an end such as 22:00 and 07:00")
+	ErrInvalidTimezone   = errors.New("invalid timezone")
+
+	// Action types the apps may not know yet are accepted as long as they look like one
+	actionTypePattern = regexp.MustCompile(`^[a-z][a-z_]{0,31}$`)
+)
+
+// GetNotificationPreferencesService returns the notification preferences of the user with the
+// channels of every known action type filled in
+func GetNotificationPreferencesService(userID string) (*models.NotificationPreferences, error) {
+	preferences, err := storage.FindNotificationPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(models.NotificationTypeSettings, len(utils.MessageTemplates))
+	for actionType := range utils.MessageTemplates {
+		types[actionType] = preferences.Channels(actionType)
+	}
+	for actionType, channels := range preferences.Types {
+		types[actionType] = channels
+	}
+	preferences.Types = types
+	return preferences, nil
+}
+
+// UpdateNotificationPreferencesService changes the channels of the listed action types and
+// replaces the quiet hours when they are sent
+func UpdateNotificationPreferencesService(userID string, update requestModels.UpdateNotificationPreferencesRequestBody) (*models.NotificationPreferences, error) {
+	preferences, err := storage.FindNotificationPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	if preferences.Types == nil {
+		preferences.Types = models.NotificationTypeSettings{}
+	}
+
+	for actionType, channels := range update.Types {
+		if !actionTypePattern.MatchString(actionType) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidActionType, actionType)
+		}
+		current := preferences.Channels(actionType)
+		if channels.InApp != nil {
+			current.InApp = *channels.InApp
+		}
+		if channels.Push != nil {
+			current.Push = *channels.Push
+		}
+		preferences.Types[actionType] = current
+	}
+
+	if quietHours := update.QuietHours; quietHours != nil {
+		if quietHours.Enabled {
+			start, startErr := time.Parse(quietHoursLayout, quietHours.Start)
+			end, endErr := time.Parse(quietHoursLayout, quietHours.End)
+			if startErr != nil || endErr != nil || start.Equal(end) {
+				return nil, ErrInvalidQuietHours
+			}
+		}
+		if _, err := time.LoadLocation(quietHours.Timezone); err != nil || (quietHours.Enabled && quietHours.Timezone == "") {
+			return nil, ErrInvalidTimezone
+		}
+		preferences.QuietHoursEnabled = quietHours.Enabled
+		preferences.QuietHoursStart = quietHours.Start
+		preferences.QuietHoursEnd = quietHours.End
+		preferences.Timezone = quietHours.Timezone
+	}
+
+	if err := storage.SaveNotificationPreferences(preferences); err != nil {
+		return nil, err
+	}
+	return GetNotificationPreferencesService(userID)
+}
+
+// inQuietHours checks if the time falls in the quiet hours of the user, quiet hours ending
+// earlier than they start run over midnight
+func inQuietHours(preferences *models.NotificationPreferences, now time.Time) bool {
+	if !preferences.QuietHoursEnabled {
+		return false
+	}
+	start, startErr := time.Parse(quietHoursLayout, preferences.QuietHoursStart)
+	end, endErr := time.Parse(quietHoursLayout, preferences.QuietHoursEnd)
+	if startErr != nil || endErr != nil {
+		return false
+	}
+	location, err := time.LoadLocation(preferences.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	local := now.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	if startMinute < endMinute {
+		return minute >= startMinute && minute < endMinute
+	}
+	return minute >= startMinute || minute < endMinute
+}
+
+// channelActionTypes splits the action types by the channels the user keeps them on
+func channelActionTypes(preferences *models.NotificationPreferences, actionTypes []string) ([]string, []string) {
+	var inApp, push []string
+	for _, actionType := range actionTypes {
+		channels := preferences.Channels(actionType)
+		if channels.InApp {
+			inApp = append(inApp, actionType)
+		}
+		if channels.Push {
+			push = append(push, actionType)
+		}
+	}
+	return inApp, push
+}
+
+// pushSkipReason tells why a queued push must not be sent anymore, the preferences may have
+// changed since it was queued. An empty reason means the push can go.
+func pushSkipReason(entry *models.PushOutbox, preferences *models.NotificationPreferences, now time.Time) (string, error) {
+	if entry.ActionType != "" && !preferences.Channels(entry.ActionType).Push {
+		return "push notifications are off for " + entry.ActionType, nil
+	}
+
+	if referenceID, err := uuid.Parse(entry.Data["reference_id"]); err == nil {
+		muted, err := storage.IsPostMuted(entry.UserID, referenceID)
+		if err != nil {
+			return "", err
+		}
+		if muted {
+			return "post is muted", nil
+		}
+	}
+
+	if inQuietHours(preferences, now) {
+		return "quiet hours", nil
+	}
+	return "", nil
+}
+
+// MutePostService stops the notifications about a post the user can see, it returns
+// gorm.ErrRecordNotFound for posts hidden from the user
+func MutePostService(postID uuid.UUID, userID string) error {
+	canView, err := storage.CanViewPost(postID, userID)
+	if err != nil {
+		log.Println("Error checking post visibility:", err)
+		return err
+	}
+	if !canView {
+		return gorm.ErrRecordNotFound
+	}
+	return storage.MutePost(userID, postID)
+}
+
+// UnmutePostService brings back the notifications about a post
+func UnmutePostService(postID uuid.UUID, userID string) error {
+	return storage.UnmutePost(userID, postID)
+}
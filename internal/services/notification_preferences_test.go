@@ -0,0 +1,141 @@
+package services
+
+import (
+	"slices"
+	"testing"
+	"time"
+	_ "time/tzdata" // The zones are not installed everywhere the tests run
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+)
+
+func quietHours(start, end, timezone string) *models.NotificationPreferences {
+	return &models.NotificationPreferences{
+		QuietHoursEnabled: true,
+		QuietHoursStart:   start,
+		QuietHoursEnd:     end,
+		Timezone:          timezone,
+	}
+}
+
+func TestInQuietHours(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 3, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name        string
+		preferences *models.NotificationPreferences
+		now         time.Time
+		want        bool
+	}{
+		{"wrapping, before start", quietHours("22:00", "07:00", "UTC"), at(21, 59), false},
+		{"wrapping, exact start", quietHours("22:00", "07:00", "UTC"), at(22, 0), true},
+		{"wrapping, midnight", quietHours("22:00", "07:00", "UTC"), at(0, 0), true},
+		{"wrapping, before end", quietHours("22:00", "07:00", "UTC"), at(6, 59), true},
+		{"wrapping, exact end", quietHours("22:00", "07:00", "UTC"), at(7, 0), false},
+		{"wrapping, afternoon", quietHours("22:00", "07:00", "UTC"), at(15, 0), false},
+		{"same day, inside", quietHours("13:00", "14:30", "UTC"), at(14, 29), true},
+		{"same day, exact end", quietHours("13:00", "14:30", "UTC"), at(14, 30), false},
+		{"same day, before start", quietHours("13:00", "14:30", "UTC"), at(12, 59), false},
+
+		// 19:30 UTC is 22:30 in Baghdad (UTC+3), 04:00 UTC is 07:00
+		{"non-UTC zone, inside", quietHours("22:00", "07:00", "Asia/Baghdad"), at(19, 30), true},
+		{"non-UTC zone, exact end", quietHours("22:00", "07:00", "Asia/Baghdad"), at(4, 0), false},
+		{"non-UTC zone, UTC night", quietHours("22:00", "07:00", "Asia/Baghdad"), at(4, 30), false},
+		{"non-UTC zone, before start", quietHours("22:00", "07:00", "Asia/Baghdad"), at(18, 59), false},
+
+		{"disabled", &models.NotificationPreferences{QuietHoursStart: "00:00", QuietHoursEnd: "23:59", Timezone: "UTC"}, at(12, 0), false},
+		{"invalid times", quietHours("10pm", "7am", "UTC"), at(23, 0), false},
+		{"unknown zone uses UTC", quietHours("22:00", "07:00", "Mars/Olympus"), at(23, 0), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inQuietHours(tt.preferences, tt.now); got != tt.want {
+				t.Errorf("inQuietHours(%s-%s %s, %s) = %v, want %v", tt.preferences.QuietHoursStart,
+					tt.preferences.QuietHoursEnd, tt.preferences.Timezone, tt.now.Format(time.Kitchen), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChannelActionTypes(t *testing.T) {
+	actionTypes := []string{"like", "comment", "mention", "reshare"}
+
+	tests := []struct {
+		name      string
+		types     models.NotificationTypeSettings
+		wantInApp []string
+		wantPush  []string
+	}{
+		{
+			name:      "no saved types",
+			types:     nil,
+			wantInApp: actionTypes,
+			wantPush:  actionTypes,
+		},
+		{
+			name: "push-only, in-app-only and muted types",
+			types: models.NotificationTypeSettings{
+				"like":    {InApp: false, Push: true},
+				"comment": {InApp: true, Push: false},
+				"reshare": {InApp: false, Push: false},
+			},
+			wantInApp: []string{"comment", "mention"},
+			wantPush:  []string{"like", "mention"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inApp, push := channelActionTypes(&models.NotificationPreferences{Types: tt.types}, actionTypes)
+			if !slices.Equal(inApp, tt.wantInApp) {
+				t.Errorf("in-app types = %v, want %v", inApp, tt.wantInApp)
+			}
+			if !slices.Equal(push, tt.wantPush) {
+				t.Errorf("push types = %v, want %v", push, tt.wantPush)
+			}
+		})
+	}
+}
+
+func TestPushSkipReason(t *testing.T) {
+	outside := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	inside := time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)
+
+	preferences := quietHours("22:00", "07:00", "UTC")
+	preferences.Types = models.NotificationTypeSettings{
+		"like":    {InApp: false, Push: true},
+		"comment": {InApp: true, Push: false},
+	}
+
+	tests := []struct {
+		name       string
+		actionType string
+		now        time.Time
+		want       string
+	}{
+		{"push-only type", "like", outside, ""},
+		{"type without entry", "mention", outside, ""},
+		{"push turned off", "comment", outside, "push notifications are off for comment"},
+		{"push turned off in quiet hours", "comment", inside, "push notifications are off for comment"},
+		{"quiet hours", "like", inside, "quiet hours"},
+		{"no action type", "", outside, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Pushes without a post reference never look up the muted posts
+			entry := &models.PushOutbox{UserID: "42", ActionType: tt.actionType, Data: models.PushData{"reference_id": "not-a-post"}}
+
+			got, err := pushSkipReason(entry, preferences, tt.now)
+			if err != nil {
+				t.Fatalf("pushSkipReason: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("pushSkipReason = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
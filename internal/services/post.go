@@ -1,13 +1,19 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"mime/multipart" // Correct import for FileHeader
-	"os"
-	"path/filepath"
+	"path"
+	"strings"
 
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/media"
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
 	"github.com/google/uuid"
 )
 
@@ -32,50 +38,288 @@ func ValidatePostForm(form *multipart.Form) (*models.Post, error) {
 		post.Body = bodies[0]
 	}
 
+	// Hashtags are always derived from the body, never trusted from the client
+	post.Hashtags = utils.ExtractHashtags(post.Body)
+
 	if authorAvatar, ok := form.Value["author_avatar"]; ok && len(authorAvatar) > 0 {
 		post.AuthorAvatar = authorAvatar[0]
 	}
 
 	if shareStates, ok := form.Value["share_state"]; ok && len(shareStates) > 0 {
-		post.ShareState = shareStates[0]
+		shareState, valid := models.NormalizeShareState(shareStates[0])
+		if !valid {
+			return nil, fmt.Errorf("invalid share_state: %s", shareStates[0])
+		}
+		post.ShareState = shareState
 	} else {
 		return nil, fmt.Errorf("share_state is required")
 	}
 
+	// Target circles may be sent as repeated fields or as a comma separated list
+	for _, circleIDs := range form.Value["circle_ids"] {
+		for _, circleID := range strings.Split(circleIDs, ",") {
+			if circleID = strings.TrimSpace(circleID); circleID != "" {
+				post.CircleIDs = append(post.CircleIDs, circleID)
+			}
+		}
+	}
+
+	// Mentioned users may be sent as repeated fields or as a comma separated list, they are
+	// validated together with the @handles of the body by ResolveMentions
+	for _, userIDs := range form.Value["mentioned_user_ids"] {
+		for _, userID := range strings.Split(userIDs, ",") {
+			if userID = strings.TrimSpace(userID); userID != "" {
+				post.MentionedUsers = append(post.MentionedUsers, models.MentionedUser{UserID: userID})
+			}
+		}
+	}
+
 	// Generate a new UUID for the post
 	post.ID = uuid.New()
 
 	return post, nil
 }
 
-// SaveImage handles saving the uploaded image and returns the path
-func SaveImage(file *multipart.FileHeader) (string, error) {
-	imageUUID := uuid.New().String()
-	imagePath := filepath.Join("uploads", imageUUID+filepath.Ext(file.Filename))
+// MaxPostMedia is the largest number of attachments a post can carry
+const MaxPostMedia = 10
+
+// maxAltTextLength caps the alt text of an attachment, in characters
+const maxAltTextLength = 1000
+
+// ErrTooManyMedia is returned when a post has more attachments than MaxPostMedia
+var ErrTooManyMedia = fmt.Errorf("a post can have at most %d attachments", MaxPostMedia)
+
+// SavePostMedia saves the uploaded images and videos of a post in order. altTexts holds the alt
+// text of each file at the same position, missing entries leave the alt text empty. When a file
+// fails the files saved before it are deleted.
+func SavePostMedia(files []*multipart.FileHeader, altTexts []string) ([]models.PostMedia, error) {
+	if len(files) > MaxPostMedia {
+		return nil, ErrTooManyMedia
+	}
+
+	postMedia := make([]models.PostMedia, 0, len(files))
+	for i, file := range files {
+		isVideo, err := isVideoFile(file)
+		if err != nil {
+			DiscardPostMedia(postMedia)
+			return nil, err
+		}
+
+		var attachment models.PostMedia
+		if isVideo {
+			if attachment, err = SaveVideo(file); err != nil {
+				DiscardPostMedia(postMedia)
+				return nil, err
+			}
+		} else {
+			variants, err := SaveImage(file)
+			if err != nil {
+				DiscardPostMedia(postMedia)
+				return nil, err
+			}
+			full := variants[models.ImageVariantFull]
+			attachment = models.PostMedia{
+				Type:     models.MediaTypeImage,
+				URL:      full.URL,
+				Width:    full.Width,
+				Height:   full.Height,
+				Variants: variants,
+			}
+		}
+
+		attachment.ID = uuid.New()
+		attachment.Position = i
+		if i < len(altTexts) {
+			attachment.AltText = truncateRunes(strings.TrimSpace(altTexts[i]), maxAltTextLength)
+		}
+		postMedia = append(postMedia, attachment)
+	}
+
+	return postMedia, nil
+}
+
+// SetPostMedia attaches the media to the post, the first image also fills the single image
+// fields read by older clients
+func SetPostMedia(post *models.Post, postMedia []models.PostMedia) {
+	post.Media = postMedia
+	post.ImageURL = ""
+	post.ImageVariants = nil
+	for i := range post.Media {
+		post.Media[i].PostID = post.ID
+		post.Media[i].Position = i
+	}
+	for _, attachment := range post.Media {
+		if attachment.Type == models.MediaTypeImage {
+			post.ImageURL = attachment.URL
+			post.ImageVariants = attachment.Variants
+			break
+		}
+	}
+}
+
+// PostMediaURLs lists the stored files of a post and of its earlier versions: every attachment,
+// the resized variants and the single image fields. Each URL is listed once.
+func PostMediaURLs(post *models.Post, postMedia []models.PostMedia, revisions []models.PostRevision) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	add := func(mediaURL string) {
+		if mediaURL != "" && !seen[mediaURL] {
+			seen[mediaURL] = true
+			urls = append(urls, mediaURL)
+		}
+	}
+
+	addMedia := func(postMedia []models.PostMedia) {
+		for _, attachment := range postMedia {
+			add(attachment.URL)
+			for _, variant := range attachment.Variants {
+				add(variant.URL)
+			}
+		}
+	}
+
+	add(post.ImageURL)
+	for _, variant := range post.ImageVariants {
+		add(variant.URL)
+	}
+	addMedia(postMedia)
+	for _, revision := range revisions {
+		add(revision.ImageURL)
+		addMedia(revision.Media)
+	}
+	return urls
+}
+
+// DiscardPostMedia deletes the files of attachments that were saved but never attached to a post
+func DiscardPostMedia(postMedia []models.PostMedia) {
+	DeleteMediaFiles(PostMediaURLs(&models.Post{}, postMedia, nil))
+}
+
+// DeleteMediaFiles removes the stored files behind the URLs, URLs from another store are skipped.
+// Nothing points to the files anymore so failures are only logged.
+func DeleteMediaFiles(urls []string) {
+	for _, mediaURL := range urls {
+		key, ok := media.Store.Key(mediaURL)
+		if !ok {
+			continue
+		}
+		if err := media.Store.Delete(context.Background(), key); err != nil {
+			log.Println("Error deleting media file:", err)
+		}
+	}
+}
+
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit])
+}
+
+// SaveImage validates the uploaded image, stores its resized variants with the configured media
+// backend and returns them. Invalid images are reported with media.ErrUnsupportedImage or
+// media.ErrImageTooLarge.
+func SaveImage(file *multipart.FileHeader) (models.ImageVariants, error) {
+	if file.Size > media.MaxImageBytes {
+		return nil, media.ErrImageTooLarge
+	}
+
+	// Open the uploaded file
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, media.MaxImageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return storeImage(data)
+}
+
+// storeImage processes an image and stores its resized variants
+func storeImage(data []byte) (models.ImageVariants, error) {
+	processed, err := media.ProcessImage(data)
+	if err != nil {
+		return nil, err
+	}
 
-	// Ensure the directory structure exists
-	if err := os.MkdirAll(filepath.Dir(imagePath), os.ModePerm); err != nil {
-		return "", fmt.Errorf("failed to create directory: %w", err)
+	// All the variants of an image share a directory named after the image
+	imageID := uuid.New().String()
+	variants := make(models.ImageVariants, len(processed))
+	for _, image := range processed {
+		key := path.Join("images", imageID, image.Name+image.Extension)
+		imageURL, err := media.Store.Save(context.Background(), key, bytes.NewReader(image.Data), int64(len(image.Data)), image.ContentType)
+		if err != nil {
+			DiscardPostMedia([]models.PostMedia{{Variants: variants}})
+			return nil, fmt.Errorf("failed to store image: %w", err)
+		}
+		variants[image.Name] = models.ImageVariant{URL: imageURL, Width: image.Width, Height: image.Height}
 	}
 
+	return variants, nil
+}
+
+// SaveVideo validates the uploaded video and stores it with the configured media backend. The
+// poster frame is extracted and stored like an image when ffmpeg is available. Invalid videos are
+// reported with media.ErrUnsupportedVideo, media.ErrVideoTooLarge or media.ErrVideoTooLong.
+func SaveVideo(file *multipart.FileHeader) (models.PostMedia, error) {
 	// Open the uploaded file
 	src, err := file.Open()
 	if err != nil {
-		return "", fmt.Errorf("failed to open file: %w", err)
+		return models.PostMedia{}, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer src.Close()
 
-	// Create a destination file
-	dst, err := os.Create(imagePath)
+	info, err := media.InspectVideo(src, file.Size)
+	if err != nil {
+		return models.PostMedia{}, err
+	}
+
+	key := path.Join("videos", uuid.New().String()+info.Extension)
+	videoURL, err := media.Store.Save(context.Background(), key, io.NewSectionReader(src, 0, file.Size), file.Size, info.ContentType)
 	if err != nil {
-		return "", fmt.Errorf("failed to create destination file: %w", err)
+		return models.PostMedia{}, fmt.Errorf("failed to store video: %w", err)
 	}
-	defer dst.Close()
 
-	// Copy the file content to the destination
-	if _, err := io.Copy(dst, src); err != nil {
-		return "", fmt.Errorf("failed to copy file: %w", err)
+	attachment := models.PostMedia{
+		Type:       models.MediaTypeVideo,
+		URL:        videoURL,
+		Width:      info.Width,
+		Height:     info.Height,
+		DurationMs: int(info.Duration.Milliseconds()),
+	}
+
+	// The poster is optional, the video is still usable without it
+	poster, err := media.ExtractPosterFrame(io.NewSectionReader(src, 0, file.Size))
+	if err != nil {
+		if !errors.Is(err, media.ErrFFmpegUnavailable) {
+			log.Println("Error extracting video poster frame:", err)
+		}
+		return attachment, nil
+	}
+	if attachment.Variants, err = storeImage(poster); err != nil {
+		log.Println("Error storing video poster frame:", err)
 	}
 
-	return imagePath, nil
+	return attachment, nil
+}
+
+// isVideoFile sniffs the first bytes of an upload to tell videos from images
+func isVideoFile(file *multipart.FileHeader) (bool, error) {
+	src, err := file.Open()
+	if err != nil {
+		return false, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer src.Close()
+
+	header := make([]byte, 12)
+	n, err := io.ReadFull(src, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("failed to read file: %w", err)
+	}
+	return media.IsVideo(header[:n]), nil
 }
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
+	"github.com/google/uuid"
+)
+
+// ErrNotPostAuthor is returned when someone other than the author tries to change a post
+var ErrNotPostAuthor = errors.New("only the author can edit this post")
+
+// PostEdit holds the changes of a post edit, nil fields are left unchanged
+type PostEdit struct {
+	Body           *string
+	ShareState     *string
+	CircleIDs      []string               // Replaces the target circles when sent
+	MentionedUsers []models.MentionedUser // Replaces the structured mentions when sent
+	HasCircleIDs   bool
+	HasMentions    bool
+	Media          []models.PostMedia // Replaces the attachments when ReplaceMedia is set
+	ReplaceMedia   bool
+}
+
+// ParsePostEditForm reads the fields of a post edit from a multipart form. New image files,
+// if any, are saved by the caller.
+func ParsePostEditForm(form *multipart.Form) (*PostEdit, error) {
+	edit := new(PostEdit)
+
+	if bodies, ok := form.Value["body"]; ok && len(bodies) > 0 {
+		edit.Body = &bodies[0]
+	}
+
+	if shareStates, ok := form.Value["share_state"]; ok && len(shareStates) > 0 {
+		shareState, valid := models.NormalizeShareState(shareStates[0])
+		if !valid {
+			return nil, fmt.Errorf("invalid share_state: %s", shareStates[0])
+		}
+		edit.ShareState = &shareState
+	}
+
+	if circleIDs, ok := form.Value["circle_ids"]; ok {
+		edit.HasCircleIDs = true
+		for _, ids := range circleIDs {
+			for _, circleID := range strings.Split(ids, ",") {
+				if circleID = strings.TrimSpace(circleID); circleID != "" {
+					edit.CircleIDs = append(edit.CircleIDs, circleID)
+				}
+			}
+		}
+	}
+
+	if userIDs, ok := form.Value["mentioned_user_ids"]; ok {
+		edit.HasMentions = true
+		for _, ids := range userIDs {
+			for _, userID := range strings.Split(ids, ",") {
+				if userID = strings.TrimSpace(userID); userID != "" {
+					edit.MentionedUsers = append(edit.MentionedUsers, models.MentionedUser{UserID: userID})
+				}
+			}
+		}
+	}
+
+	// Without new files remove_image drops every attachment
+	if removeImage, ok := form.Value["remove_image"]; ok && len(removeImage) > 0 && removeImage[0] == "true" {
+		edit.ReplaceMedia = true
+	}
+
+	return edit, nil
+}
+
+// EditPostService applies an edit made by the author of a post. The previous version is kept
+// as a revision, and the hashtags and mentions are derived again from the new content. Users
+// mentioned for the first time are notified.
+func EditPostService(postID uuid.UUID, userID string, edit *PostEdit) (*models.Post, error) {
+	post, err := storage.GetPostByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.AuthorID != userID {
+		return nil, ErrNotPostAuthor
+	}
+
+	// Replaced attachments keep their files, the revision still shows them until the post is deleted
+	previousMedia, err := storage.GetPostMedia(post.ID)
+	if err != nil {
+		return nil, err
+	}
+	post.Media = previousMedia
+
+	// Snapshot the current version before changing anything
+	revision := &models.PostRevision{
+		PostID:     post.ID,
+		Body:       post.Body,
+		ImageURL:   post.ImageURL,
+		Media:      previousMedia,
+		ShareState: post.ShareState,
+		CircleIDs:  post.CircleIDs,
+	}
+	previousMentions := post.MentionedUsers
+
+	if edit.Body != nil {
+		post.Body = *edit.Body
+	}
+	if edit.ReplaceMedia {
+		SetPostMedia(post, edit.Media)
+	}
+	if edit.ShareState != nil {
+		post.ShareState = *edit.ShareState
+	}
+	if edit.HasCircleIDs {
+		post.CircleIDs = edit.CircleIDs
+	}
+
+	// Make sure the post still only targets the author's own circles
+	if err := ValidatePostCircles(post, userID); err != nil {
+		return nil, err
+	}
+
+	// Nothing to save when the visible content didn't change
+	if post.Body == revision.Body && post.ImageURL == revision.ImageURL &&
+		post.ShareState == revision.ShareState && slices.Equal(post.CircleIDs, revision.CircleIDs) &&
+		!edit.HasMentions && !edit.ReplaceMedia {
+		return post, nil
+	}
+
+	// Hashtags, mentions and the link preview always follow the current body
+	post.Hashtags = utils.ExtractHashtags(post.Body)
+	previewLink := AttachLinkPreview(post)
+	requestedMentions := []models.MentionedUser(previousMentions)
+	if edit.HasMentions {
+		requestedMentions = edit.MentionedUsers
+	}
+	post.MentionedUsers, err = ResolveMentions(userID, post.Body, requestedMentions)
+	if err != nil {
+		return nil, err
+	}
+
+	editedAt := time.Now()
+	post.EditedAt = &editedAt
+
+	if err := storage.UpdatePostWithRevision(post, revision, edit.ReplaceMedia); err != nil {
+		return nil, err
+	}
+	FetchLinkPreview(post, previewLink)
+
+	// Only users who were not mentioned before get a notification
+	var newMentions models.MentionedUserArray
+	for _, mention := range post.MentionedUsers {
+		if !isMentioned(previousMentions, mention.UserID) {
+			newMentions = append(newMentions, mention)
+		}
+	}
+	NotifyPostMentions(post, newMentions)
+
+	return post, nil
+}
+
+// GetPostRevisionsService returns the edit history of a post, only its author can see it
+func GetPostRevisionsService(postID uuid.UUID, userID string) ([]models.PostRevision, error) {
+	post, err := storage.GetPostByID(postID)
+	if err != nil {
+		return nil, err
+	}
+	if post.AuthorID != userID {
+		return nil, ErrNotPostAuthor
+	}
+
+	return storage.GetPostRevisions(postID)
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/push"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
+	"github.com/google/uuid"
+)
+
+// Delivery settings of the push worker
+const (
+	MaxPushAttempts = 8 // Attempts before a push is dead, about an hour of retries
+
+	pushPollInterval      = 5 * time.Second
+	pushBatchSize         = 50
+	pushSendTimeout       = 10 * time.Second
+	pushLease             = pushBatchSize*pushSendTimeout + time.Minute // A batch where every send times out still finishes in time
+	pushBaseBackoff       = 30 * time.Second
+	pushMaxBackoff        = time.Hour
+	finishedPushRetention = 7 * 24 * time.Hour
+
+	// Expo has the receipts ready about 15 minutes after sending and keeps them for a day
+	pushReceiptInterval  = 5 * time.Minute
+	pushReceiptDelay     = 15 * time.Minute
+	pushReceiptRetention = 24 * time.Hour
+)
+
+// ErrInvalidPushStatus is returned when the push outbox is filtered by an unknown status
+var ErrInvalidPushStatus = errors.New("invalid push status")
+
+// newPushes builds the outbox entries of a notification, one for each active device of the user
+func newPushes(notifyUser *models.User, notification *models.Notification, actionType string) ([]models.PushOutbox, error) {
+	devices, err := storage.FindActiveUserDevices(notifyUser.ID, time.Now().Add(-DeviceActiveWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	pushes := make([]models.PushOutbox, 0, len(devices))
+	for _, device := range devices {
+		// Devices set to a language with translated messages get them in that language
+		lang := utils.NotificationLanguage(device.Locale, notifyUser.UserLang)
+		message := utils.NewPushMessage(device.Token, lang, notification)
+		pushes = append(pushes, models.PushOutbox{
+			ID:            uuid.New(),
+			UserID:        notifyUser.ID,
+			ActionType:    actionType,
+			Token:         message.Token,
+			Title:         message.Title,
+			Body:          message.Body,
+			Data:          message.Data,
+			Status:        models.PushStatusPending,
+			NextAttemptAt: time.Now(),
+		})
+	}
+	return pushes, nil
+}
+
+// StartPushWorker delivers the queued pushes in the background until the context is done.
+// Every instance can run a worker, each push is claimed by a single one.
+func StartPushWorker(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(pushPollInterval)
+		defer ticker.Stop()
+
+		lastCleanup, lastReceiptCheck := time.Time{}, time.Time{}
+		for {
+			deliverDuePushes(ctx)
+
+			if time.Since(lastReceiptCheck) > pushReceiptInterval {
+				checkPushReceipts(ctx)
+				lastReceiptCheck = time.Now()
+			}
+
+			if time.Since(lastCleanup) > time.Hour {
+				if _, err := storage.DeleteFinishedPushesBefore(time.Now().Add(-finishedPushRetention)); err != nil {
+					log.Println("Error deleting finished pushes:", err)
+				}
+				lastCleanup = time.Now()
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// deliverDuePushes sends the pushes whose attempt is due, batch after batch until none is left
+func deliverDuePushes(ctx context.Context) {
+	for ctx.Err() == nil {
+		pushes, err := storage.ClaimDuePushes(pushBatchSize, pushLease)
+		if err != nil {
+			log.Println("Error claiming pushes:", err)
+			return
+		}
+
+		// The preferences of each user are loaded once per batch
+		preferences := make(map[string]*models.NotificationPreferences)
+		for i := range pushes {
+			userPreferences, ok := preferences[pushes[i].UserID]
+			if !ok {
+				userPreferences, err = storage.FindNotificationPreferences(pushes[i].UserID)
+				if err != nil {
+					// The push is claimed again once its lease runs out
+					log.Println("Error fetching notification preferences:", err)
+					continue
+				}
+				preferences[pushes[i].UserID] = userPreferences
+			}
+			deliverPush(ctx, &pushes[i], userPreferences)
+		}
+		if len(pushes) < pushBatchSize {
+			return
+		}
+	}
+}
+
+// deliverPush sends one push and records the outcome. Pushes the preferences of the user hold
+// back, during their quiet hours for example, are skipped. Failed pushes are retried with an
+// exponential backoff, they are dead after MaxPushAttempts or when the token is invalid.
+func deliverPush(ctx context.Context, entry *models.PushOutbox, preferences *models.NotificationPreferences) {
+	reason, err := pushSkipReason(entry, preferences, time.Now())
+	if err != nil {
+		// The push is claimed again once its lease runs out
+		log.Println("Error checking push preferences:", err)
+		return
+	}
+	if reason != "" {
+		if err := storage.MarkPushSkipped(entry, reason); err != nil {
+			log.Println("Error recording skipped push:", err)
+		}
+		return
+	}
+
+	err = attemptPush(ctx, entry, time.Now())
+	if err == nil {
+		if err := storage.MarkPushSent(entry); err != nil {
+			log.Println("Error recording sent push:", err)
+		}
+		return
+	}
+
+	invalidToken := errors.Is(err, push.ErrInvalidToken)
+	if invalidToken {
+		forgetPushToken(entry.UserID, entry.Token)
+	}
+	if entry.Status == models.PushStatusDead {
+		log.Printf("Push %s is dead after %d attempts: %v", entry.ID, entry.Attempts, err)
+	}
+
+	if err := storage.MarkPushFailed(entry, invalidToken); err != nil {
+		log.Println("Error recording failed push:", err)
+	}
+}
+
+// attemptPush sends a push and moves it to its next state without saving it: sent, dead when
+// the token is invalid or after MaxPushAttempts, otherwise due again once the backoff is over.
+// The error of a failed send is returned.
+func attemptPush(ctx context.Context, entry *models.PushOutbox, now time.Time) error {
+	sendCtx, cancel := context.WithTimeout(ctx, pushSendTimeout)
+	defer cancel()
+
+	result, err := utils.SendPushNotification(sendCtx, push.Message{
+		Token: entry.Token,
+		Title: entry.Title,
+		Body:  entry.Body,
+		Data:  entry.Data,
+	})
+	entry.Attempts++
+
+	if err == nil {
+		entry.Status = models.PushStatusSent
+		entry.Provider = result.Provider
+		entry.TicketID = result.TicketID
+		return nil
+	}
+
+	entry.LastError = err.Error()
+	if errors.Is(err, push.ErrInvalidToken) || entry.Attempts >= MaxPushAttempts {
+		entry.Status = models.PushStatusDead
+	} else {
+		entry.NextAttemptAt = now.Add(pushBackoff(entry.Attempts))
+	}
+	return err
+}
+
+// checkPushReceipts records the receipts of the pushes sent through Expo. Devices the app was
+// uninstalled from are unregistered.
+func checkPushReceipts(ctx context.Context) {
+	if push.Receipts == nil {
+		return
+	}
+
+	now := time.Now()
+	var cursor *utils.Cursor
+	for ctx.Err() == nil {
+		pushes, err := storage.FindPushesAwaitingReceipt(push.TokenTypeExpo, now.Add(-pushReceiptDelay), push.MaxExpoReceiptIDs, cursor)
+		if err != nil {
+			log.Println("Error fetching pushes awaiting a receipt:", err)
+			return
+		}
+		if len(pushes) == 0 {
+			return
+		}
+
+		ticketIDs := make([]string, len(pushes))
+		for i, entry := range pushes {
+			ticketIDs[i] = entry.TicketID
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, pushSendTimeout)
+		receipts, err := push.Receipts.CheckReceipts(checkCtx, ticketIDs)
+		cancel()
+		if err != nil {
+			log.Println("Error checking push receipts:", err)
+			return
+		}
+
+		for i := range pushes {
+			recordPushReceipt(&pushes[i], receipts, now)
+		}
+
+		last := pushes[len(pushes)-1]
+		cursor = &utils.Cursor{Time: *last.SentAt, ID: last.ID}
+		if len(pushes) < push.MaxExpoReceiptIDs {
+			return
+		}
+	}
+}
+
+// recordPushReceipt saves the receipt of a push, pushes without a receipt are checked again
+// later until the provider no longer keeps it
+func recordPushReceipt(entry *models.PushOutbox, receipts map[string]push.Receipt, now time.Time) {
+	receipt, ok := receipts[entry.TicketID]
+	var err error
+	switch {
+	case !ok && now.Sub(*entry.SentAt) > pushReceiptRetention:
+		err = storage.RecordPushReceipt(entry, models.PushReceiptExpired, "", false)
+	case !ok:
+		return
+	case receipt.Delivered:
+		err = storage.RecordPushReceipt(entry, models.PushReceiptDelivered, "", false)
+	default:
+		if receipt.InvalidToken {
+			forgetPushToken(entry.UserID, entry.Token)
+		}
+		err = storage.RecordPushReceipt(entry, models.PushReceiptFailed, receipt.Error, receipt.InvalidToken)
+	}
+	if err != nil {
+		log.Println("Error recording push receipt:", err)
+	}
+}
+
+// forgetPushToken unregisters the device of a token the provider reported as invalid or
+// unregistered so no more pushes are queued for it
+func forgetPushToken(userID string, token string) {
+	if err := storage.DeleteUserDeviceByToken(userID, token); err != nil {
+		log.Println("Error removing device of invalid push token:", err)
+	}
+}
+
+// pushBackoff is the wait before the next attempt, doubling after every failed attempt
+func pushBackoff(attempts int) time.Duration {
+	backoff := pushBaseBackoff
+	for i := 1; i < attempts && backoff < pushMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, pushMaxBackoff)
+}
+
+// FetchPushOutboxService lists the pushes with the given status for inspection, dead pushes by default
+func FetchPushOutboxService(status string, limit int, cursor *utils.Cursor) ([]models.PushOutbox, error) {
+	switch status {
+	case "":
+		status = models.PushStatusDead
+	case models.PushStatusPending, models.PushStatusSent, models.PushStatusDead, models.PushStatusSkipped:
+	default:
+		return nil, ErrInvalidPushStatus
+	}
+	return storage.FetchPushOutbox(status, limit, cursor)
+}
+
+// CountPushOutboxService counts the pushes of each status
+func CountPushOutboxService() (map[string]int64, error) {
+	return storage.CountPushOutboxByStatus()
+}
+
+// FindDeliveryStatsService retrieves the delivery stats of a notification, it returns
+// gorm.ErrRecordNotFound when no push was ever queued for it
+func FindDeliveryStatsService(notificationID uuid.UUID) (*models.NotificationDeliveryStats, error) {
+	return storage.FindDeliveryStats(notificationID)
+}
+
+// RequeuePushService queues a dead push again, it returns gorm.ErrRecordNotFound when there is
+// no dead push with this ID
+func RequeuePushService(pushID uuid.UUID) error {
+	if err := storage.RequeueDeadPush(pushID); err != nil {
+		return fmt.Errorf("failed to requeue push: %w", err)
+	}
+	return nil
+}
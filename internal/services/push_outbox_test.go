@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/push"
+	"github.com/google/uuid"
+)
+
+// useRecordingSender routes the pushes of a test to a recording sender
+func useRecordingSender(t *testing.T) *push.RecordingSender {
+	t.Helper()
+	sender := push.NewRecordingSender()
+	previous := push.Sender
+	push.Sender = sender
+	t.Cleanup(func() { push.Sender = previous })
+	return sender
+}
+
+func newTestPush(attempts int) *models.PushOutbox {
+	return &models.PushOutbox{
+		ID:         uuid.New(),
+		UserID:     "42",
+		ActionType: "like",
+		Token:      "ExponentPushToken[xxxxxxxxxxxxxxxxxxxxxx]",
+		Title:      "New like",
+		Body:       "Sara liked your post",
+		Data:       models.PushData{"type": "like"},
+		Status:     models.PushStatusPending,
+		Attempts:   attempts,
+	}
+}
+
+func TestPushBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := pushBackoff(tt.attempts); got != tt.want {
+			t.Errorf("pushBackoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestAttemptPushSent(t *testing.T) {
+	sender := useRecordingSender(t)
+	entry := newTestPush(2)
+
+	if err := attemptPush(context.Background(), entry, time.Now()); err != nil {
+		t.Fatalf("attemptPush: %v", err)
+	}
+	if entry.Status != models.PushStatusSent || entry.Attempts != 3 {
+		t.Errorf("status = %s after %d attempts, want sent after 3", entry.Status, entry.Attempts)
+	}
+	if entry.Provider != push.TokenTypeExpo || entry.TicketID == "" {
+		t.Errorf("provider = %q, ticket = %q, want the expo ticket", entry.Provider, entry.TicketID)
+	}
+
+	messages := sender.Messages()
+	if len(messages) != 1 || messages[0].Token != entry.Token || messages[0].Title != entry.Title ||
+		messages[0].Body != entry.Body || messages[0].Data["type"] != "like" {
+		t.Errorf("sent %+v, want the push of the entry", messages)
+	}
+}
+
+func TestAttemptPushFailures(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		err          error
+		attempts     int
+		wantStatus   string
+		wantNextTime time.Time
+	}{
+		{"invalid token on the first attempt", push.ErrInvalidToken, 0, models.PushStatusDead, time.Time{}},
+		{"wrapped invalid token", errors.Join(errors.New("expo"), push.ErrInvalidToken), 3, models.PushStatusDead, time.Time{}},
+		{"first failure", errors.New("timeout"), 0, models.PushStatusPending, now.Add(30 * time.Second)},
+		{"seventh failure", errors.New("timeout"), 6, models.PushStatusPending, now.Add(32 * time.Minute)},
+		{"eighth failure", errors.New("timeout"), MaxPushAttempts - 1, models.PushStatusDead, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := useRecordingSender(t)
+			sender.Err = tt.err
+			entry := newTestPush(tt.attempts)
+
+			err := attemptPush(context.Background(), entry, now)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if entry.Attempts != tt.attempts+1 {
+				t.Errorf("attempts = %d, want %d", entry.Attempts, tt.attempts+1)
+			}
+			if entry.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", entry.Status, tt.wantStatus)
+			}
+			if !entry.NextAttemptAt.Equal(tt.wantNextTime) {
+				t.Errorf("next attempt at %s, want %s", entry.NextAttemptAt, tt.wantNextTime)
+			}
+			if entry.LastError == "" {
+				t.Error("last error was not recorded")
+			}
+		})
+	}
+}
+
+func TestAttemptPushWithoutSender(t *testing.T) {
+	previous := push.Sender
+	push.Sender = nil
+	t.Cleanup(func() { push.Sender = previous })
+
+	entry := newTestPush(0)
+	now := time.Now()
+	if err := attemptPush(context.Background(), entry, now); err == nil {
+		t.Fatal("attemptPush succeeded without a sender")
+	}
+	// A missing setup is retried like any other failure
+	if entry.Status != models.PushStatusPending || !entry.NextAttemptAt.After(now) {
+		t.Errorf("status = %s, next attempt at %s, want a retry", entry.Status, entry.NextAttemptAt)
+	}
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"log"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/realtime"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+)
+
+// publishNotification streams a new or updated notification to its user together with the
+// new unread count. The notification is already saved so failures are only logged.
+func publishNotification(notification *models.Notification) {
+	count, err := storage.CountUnreadNotifications(notification.UserID)
+	if err != nil {
+		log.Println("Error counting unread notifications:", err)
+		return
+	}
+
+	event := realtime.Event{
+		Type:           realtime.EventNotification,
+		UserID:         notification.UserID,
+		NotificationID: notification.ID.String(),
+		UnreadCount:    count,
+	}
+	if err := realtime.Publish(event); err != nil {
+		log.Println("Error publishing notification event:", err)
+	}
+}
+
+// publishUnreadCount streams the unread count of the user after their notifications changed,
+// failures are only logged
+func publishUnreadCount(userID string) {
+	count, err := storage.CountUnreadNotifications(userID)
+	if err != nil {
+		log.Println("Error counting unread notifications:", err)
+		return
+	}
+
+	event := realtime.Event{Type: realtime.EventUnreadCount, UserID: userID, UnreadCount: count}
+	if err := realtime.Publish(event); err != nil {
+		log.Println("Error publishing unread count event:", err)
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/storage"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
+	"github.com/google/uuid"
+)
+
+// ErrReshareNotAllowed is returned when the original post is not public
+var ErrReshareNotAllowed = errors.New("only public posts can be reshared")
+
+// ResharePostService reshares a post with optional commentary. Resharing a reshare
+// points the new post to the original. It returns gorm.ErrRecordNotFound when the
+// user is not allowed to see the post.
+func ResharePostService(postID uuid.UUID, userID string, shareText string, shareState string, circleIDs []string) (*models.Post, error) {
+	// Fetch the post, posts the user can't see are reported as not found
+	original, err := storage.GetPostByIDForViewer(postID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Always point to the original post instead of building chains of reshares
+	if original.ResharedPostID != nil {
+		original, err = storage.GetPostByIDForViewer(*original.ResharedPostID, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Limited posts can't be reshared, it would widen their audience
+	if original.ShareState != models.ShareStatePublic {
+		return nil, ErrReshareNotAllowed
+	}
+
+	// Fetch the user who reshares
+	resharer, err := storage.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if shareState == "" {
+		shareState = models.ShareStatePublic
+	}
+	normalizedShareState, valid := models.NormalizeShareState(shareState)
+	if !valid {
+		return nil, fmt.Errorf("invalid share_state: %s", shareState)
+	}
+
+	reshare := &models.Post{
+		ID:             uuid.New(),
+		AuthorID:       resharer.ID,
+		AuthorName:     resharer.Username,
+		AuthorAvatar:   resharer.ProfileAvatar,
+		Body:           shareText,
+		Hashtags:       utils.ExtractHashtags(shareText),
+		ShareState:     normalizedShareState,
+		CircleIDs:      circleIDs,
+		ResharedPostID: &original.ID,
+	}
+
+	// Make sure the reshare only targets the user's own circles
+	if err := ValidatePostCircles(reshare, resharer.ID); err != nil {
+		return nil, err
+	}
+
+	// Resolve the users mentioned in the share text
+	reshare.MentionedUsers, err = ResolveMentions(resharer.ID, shareText, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The preview of the first link of the share text is fetched once the reshare is saved
+	previewLink := AttachLinkPreview(reshare)
+
+	// Save the reshare
+	if err := storage.CreatePost(*reshare); err != nil {
+		return nil, err
+	}
+	FetchLinkPreview(reshare, previewLink)
+
+	// Increment the reshares counter of the original post
+	if err := storage.UpdatePostResharesCount(original.ID, 1); err != nil {
+		return nil, err
+	}
+	original.ResharesCount++
+
+	// Notify the author of the original post, the reshare is already saved so a failure is only logged
+	if original.AuthorID != resharer.ID {
+		notifyUser, err := storage.FindUserByID(original.AuthorID)
+		if err == nil {
+			actionTypes := []string{"reshare"}
+			if _, err := CreateOrUpdateNotification(notifyUser, resharer.ID, actionTypes, original.ID, original.Body); err != nil {
+				log.Println("Error creating reshare notification:", err)
+			}
+		}
+	}
+
+	// Notify the users mentioned in the share text
+	NotifyPostMentions(reshare, reshare.MentionedUsers)
+
+	reshare.ResharedPost = original
+	return reshare, nil
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateCircle creates a new circle in the database
+func CreateCircle(circle *models.Circle) error {
+	if err := database.DB.Create(circle).Error; err != nil {
+		return fmt.Errorf("could not create circle: %w", err)
+	}
+	return nil
+}
+
+// FindCircleByID retrieves a circle by its ID
+func FindCircleByID(circleID uuid.UUID) (*models.Circle, error) {
+	var circle models.Circle
+	if err := database.DB.Where("id = ?", circleID).First(&circle).Error; err != nil {
+		return nil, err
+	}
+	return &circle, nil
+}
+
+// GetCirclesByUserID retrieves all circles owned by a user, ordered by name
+func GetCirclesByUserID(userID string) ([]models.Circle, error) {
+	var circles []models.Circle
+	if err := database.DB.Where("user_id = ?", userID).Order("name ASC").Find(&circles).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch circles: %w", err)
+	}
+	return circles, nil
+}
+
+// CountCirclesOwnedBy counts how many of the given circle IDs belong to the user
+func CountCirclesOwnedBy(userID string, circleIDs []string) (int64, error) {
+	var count int64
+	if err := database.DB.Model(&models.Circle{}).
+		Where("user_id = ? AND id IN ?", userID, circleIDs).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count circles: %w", err)
+	}
+	return count, nil
+}
+
+// UpdateCircle saves the changes made to a circle
+func UpdateCircle(circle *models.Circle) error {
+	return database.DB.Save(circle).Error
+}
+
+// DeleteCircle removes a circle and all of its members
+func DeleteCircle(circleID uuid.UUID) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("circle_id = ?", circleID).Delete(&models.CircleMember{}).Error; err != nil {
+			return fmt.Errorf("could not delete members for circle %v: %w", circleID, err)
+		}
+		if err := tx.Delete(&models.Circle{}, "id = ?", circleID).Error; err != nil {
+			return fmt.Errorf("could not delete circle %v: %w", circleID, err)
+		}
+		return nil
+	})
+}
+
+// AddCircleMembers adds users to a circle, members that already exist are ignored
+func AddCircleMembers(members []models.CircleMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error; err != nil {
+		return fmt.Errorf("failed to add circle members: %w", err)
+	}
+	return nil
+}
+
+// RemoveCircleMember removes a user from a circle
+func RemoveCircleMember(circleID uuid.UUID, memberID string) error {
+	if err := database.DB.Where("circle_id = ? AND member_id = ?", circleID, memberID).
+		Delete(&models.CircleMember{}).Error; err != nil {
+		return fmt.Errorf("failed to remove circle member: %w", err)
+	}
+	return nil
+}
+
+// GetCircleMembers retrieves the users that belong to a circle
+func GetCircleMembers(circleID uuid.UUID, limit, offset int) ([]models.User, error) {
+	var users []models.User
+
+	if err := database.DB.Joins("JOIN circle_members ON circle_members.member_id = users.id").
+		Where("circle_members.circle_id = ?", circleID).
+		Order("circle_members.added_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch circle members: %w", err)
+	}
+
+	return users, nil
+}
@@ -5,7 +5,9 @@ import (
 
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // SaveComment saves a comment in the database
@@ -39,3 +41,111 @@ func DeleteComment(commentID uuid.UUID) error {
 	}
 	return nil
 }
+
+// DeleteRepliesByParentID deletes all replies of a comment and returns how many were removed
+func DeleteRepliesByParentID(parentID uuid.UUID) (int64, error) {
+	result := database.DB.Where("parent_id = ?", parentID).Delete(&models.Comment{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("could not delete replies for comment %v: %w", parentID, result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// UpdateCommentRepliesCount adds delta to the replies counter of a comment
+func UpdateCommentRepliesCount(commentID uuid.UUID, delta int) error {
+	if err := database.DB.Model(&models.Comment{}).Where("id = ?", commentID).
+		UpdateColumn("replies_count", gorm.Expr("GREATEST(replies_count + ?, 0)", delta)).Error; err != nil {
+		return fmt.Errorf("failed to update replies counter: %w", err)
+	}
+	return nil
+}
+
+// UpdateCommentLikesCount adds delta to the likes counter of a comment
+func UpdateCommentLikesCount(commentID uuid.UUID, delta int) error {
+	if err := database.DB.Model(&models.Comment{}).Where("id = ?", commentID).
+		UpdateColumn("likes_count", gorm.Expr("GREATEST(likes_count + ?, 0)", delta)).Error; err != nil {
+		return fmt.Errorf("failed to update likes counter: %w", err)
+	}
+	return nil
+}
+
+// oldestCommentsFirst orders comments from oldest to newest and starts after the cursor when one is given
+func oldestCommentsFirst(cursor *utils.Cursor) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if cursor != nil {
+			db = db.Where("(created_at, id) > (?, ?)", cursor.Time, cursor.ID)
+		}
+		return db.Order("created_at ASC").Order("id ASC")
+	}
+}
+
+// FetchComments retrieves the top level comments of a post, oldest first, starting after the cursor
+func FetchComments(postID uuid.UUID, limit int, cursor *utils.Cursor) ([]models.Comment, error) {
+	var comments []models.Comment
+
+	if err := database.DB.Scopes(oldestCommentsFirst(cursor)).
+		Where("post_id = ? AND parent_id IS NULL", postID).
+		Limit(limit).
+		Find(&comments).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch comments: %w", err)
+	}
+
+	return comments, nil
+}
+
+// FetchReplies retrieves the replies of a comment, oldest first, starting after the cursor
+func FetchReplies(parentID uuid.UUID, limit int, cursor *utils.Cursor) ([]models.Comment, error) {
+	var replies []models.Comment
+
+	if err := database.DB.Scopes(oldestCommentsFirst(cursor)).
+		Where("parent_id = ?", parentID).
+		Limit(limit).
+		Find(&replies).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch replies: %w", err)
+	}
+
+	return replies, nil
+}
+
+// UpdateCommentWithRevision saves an edited comment together with the revision holding its previous version.
+// Only the edited columns are written, so the counters updated in the meantime are kept.
+func UpdateCommentWithRevision(comment *models.Comment, revision *models.CommentRevision) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(revision).Error; err != nil {
+			return fmt.Errorf("could not save comment revision: %w", err)
+		}
+		if err := tx.Model(comment).
+			Select("content", "mentioned_users", "edited", "edited_at").
+			Updates(comment).Error; err != nil {
+			return fmt.Errorf("could not update comment: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetCommentRevisions retrieves the previous versions of a comment, most recent first
+func GetCommentRevisions(commentID uuid.UUID) ([]models.CommentRevision, error) {
+	var revisions []models.CommentRevision
+	if err := database.DB.Where("comment_id = ?", commentID).Order("created_at DESC").Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch comment revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+// DeleteCommentRevisions deletes the edit history of a comment and of its replies
+func DeleteCommentRevisions(commentID uuid.UUID) error {
+	if err := database.DB.Where("comment_id = ? OR comment_id IN (SELECT id FROM comments WHERE parent_id = ?)", commentID, commentID).
+		Delete(&models.CommentRevision{}).Error; err != nil {
+		return fmt.Errorf("could not delete revisions for comment %v: %w", commentID, err)
+	}
+	return nil
+}
+
+// DeleteCommentRevisionsByPostID deletes the edit history of every comment on a post
+func DeleteCommentRevisionsByPostID(postID uuid.UUID) error {
+	if err := database.DB.Where("comment_id IN (SELECT id FROM comments WHERE post_id = ?)", postID).
+		Delete(&models.CommentRevision{}).Error; err != nil {
+		return fmt.Errorf("could not delete comment revisions for post %v: %w", postID, err)
+	}
+	return nil
+}
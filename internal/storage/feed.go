@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
+)
+
+// FeedRanking holds the weights used to score posts in the home feed
+type FeedRanking struct {
+	RecencyWeight        float64 // Weight of how new the post is
+	RecencyHalfLifeHours float64 // Hours after which the recency score is halved
+	EngagementWeight     float64 // Weight of the likes, comments and reshares of the post
+	AffinityWeight       float64 // Weight of the viewer's past likes and comments on the author's posts
+	FollowWeight         float64 // Bonus when the viewer follows the author
+	MaxAgeHours          float64 // Posts older than this are not part of the feed
+}
+
+// feedScoreExpression scores a post from its age, its engagement and the viewer's relation with the author
+const feedScoreExpression = `? * POWER(0.5, GREATEST(EXTRACT(EPOCH FROM (?::timestamptz - posts.created_at)), 0) / 3600.0 / ?)
+	+ ? * LN(1 + posts.likes_count + 2 * posts.comments_count + 3 * posts.reshares_count)
+	+ ? * LN(1 + COALESCE(affinity.interactions, 0))
+	+ ? * (CASE WHEN follows.follower_id IS NULL THEN 0 ELSE 1 END)`
+
+// affinityQuery counts the viewer's likes and comments per author of the liked or commented posts,
+// the likes the viewer gave to comments are not counted
+const affinityQuery = `(SELECT posts.author_id, COUNT(*) AS interactions FROM (
+		SELECT post_id FROM likes WHERE user_id = ? AND comment_id IS NULL
+		UNION ALL
+		SELECT post_id FROM comments WHERE user_id = ?
+	) interactions JOIN posts ON posts.id = interactions.post_id
+	WHERE posts.author_id <> ?
+	GROUP BY posts.author_id) affinity`
+
+// maxFeedSnapshotPosts is the number of best scored posts kept in the snapshot of a feed session
+const maxFeedSnapshotPosts = 1000
+
+// CreateFeedSnapshot scores the posts visible to the viewer as of asOf and stores the best ones as
+// the feed snapshot read by GetHomeFeed, only posts from the last MaxAgeHours are candidates
+func CreateFeedSnapshot(viewerID string, ranking FeedRanking, asOf time.Time) error {
+	scored := database.DB.Model(&models.Post{}).
+		Select("?, ?::timestamptz, posts.id, ("+feedScoreExpression+")::double precision AS score",
+			viewerID, asOf,
+			ranking.RecencyWeight, asOf, ranking.RecencyHalfLifeHours,
+			ranking.EngagementWeight,
+			ranking.AffinityWeight,
+			ranking.FollowWeight,
+		).
+		Joins("LEFT JOIN "+affinityQuery+" ON affinity.author_id = posts.author_id", viewerID, viewerID, viewerID).
+		Joins("LEFT JOIN follows ON follows.follower_id = ? AND follows.following_id::text = posts.author_id", viewerID).
+		Scopes(visibleTo(viewerID)).
+		Where("posts.created_at <= ? AND posts.created_at > ?", asOf, asOf.Add(-time.Duration(ranking.MaxAgeHours*float64(time.Hour)))).
+		Order("score DESC").
+		Limit(maxFeedSnapshotPosts)
+
+	if err := database.DB.Exec("INSERT INTO feed_entries (viewer_id, as_of, post_id, score) ? ON CONFLICT DO NOTHING", scored).Error; err != nil {
+		return fmt.Errorf("failed to create feed snapshot: %w", err)
+	}
+	return nil
+}
+
+// DeleteFeedSnapshotsBefore removes the feed snapshots taken before the given time
+func DeleteFeedSnapshotsBefore(before time.Time) error {
+	if err := database.DB.Where("as_of < ?", before).Delete(&models.FeedEntry{}).Error; err != nil {
+		return fmt.Errorf("failed to delete feed snapshots: %w", err)
+	}
+	return nil
+}
+
+// GetHomeFeed retrieves the posts of the viewer's feed snapshot taken at asOf, ranked by their
+// score in the snapshot. Posts the viewer can't see anymore are left out.
+func GetHomeFeed(viewerID string, asOf time.Time, limit int, cursor *utils.Cursor) ([]models.Post, error) {
+	var posts []models.Post
+
+	query := database.DB.Model(&models.Post{}).
+		Select("posts.*, feed_entries.score").
+		Joins("JOIN feed_entries ON feed_entries.post_id = posts.id AND feed_entries.viewer_id = ? AND feed_entries.as_of = ?", viewerID, asOf).
+		Scopes(visibleTo(viewerID), withResharedPost(viewerID), withMedia)
+	if cursor != nil {
+		query = query.Where("(feed_entries.score, posts.id) < (?, ?)", cursor.Score, cursor.ID)
+	}
+
+	if err := query.
+		Order("feed_entries.score DESC").
+		Order("posts.id DESC").
+		Limit(limit).
+		Find(&posts).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch home feed: %w", err)
+	}
+
+	return posts, nil
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+)
+
+// FindFollow fetches the follow relationship between two users
+func FindFollow(followerID, followingID string) (*models.Follow, error) {
+	var follow models.Follow
+	err := database.DB.Where("follower_id = ? AND following_id = ?", followerID, followingID).First(&follow).Error
+	if err != nil {
+		return nil, err
+	}
+	return &follow, nil
+}
+
+// CreateFollow creates a new follow relationship in the database
+func CreateFollow(follow *models.Follow) error {
+	if err := database.DB.Create(follow).Error; err != nil {
+		return fmt.Errorf("failed to follow user: %w", err)
+	}
+	return nil
+}
+
+// DeleteFollow removes the follow relationship between two users
+func DeleteFollow(followerID, followingID string) error {
+	if err := database.DB.Where("follower_id = ? AND following_id = ?", followerID, followingID).
+		Delete(&models.Follow{}).Error; err != nil {
+		return fmt.Errorf("failed to unfollow user: %w", err)
+	}
+	return nil
+}
+
+// GetFollowers retrieves the users following the given user, newest first
+func GetFollowers(userID string, limit, offset int) ([]models.User, error) {
+	var users []models.User
+
+	if err := database.DB.Joins("JOIN follows ON follows.follower_id = users.id").
+		Where("follows.following_id = ?", userID).
+		Order("follows.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch followers: %w", err)
+	}
+
+	return users, nil
+}
+
+// GetFollowing retrieves the users the given user follows, newest first
+func GetFollowing(userID string, limit, offset int) ([]models.User, error) {
+	var users []models.User
+
+	if err := database.DB.Joins("JOIN follows ON follows.following_id = users.id").
+		Where("follows.follower_id = ?", userID).
+		Order("follows.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch following: %w", err)
+	}
+
+	return users, nil
+}
+
+// CountFollowers returns how many users follow the given user
+func CountFollowers(userID string) (int64, error) {
+	var count int64
+	if err := database.DB.Model(&models.Follow{}).Where("following_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count followers: %w", err)
+	}
+	return count, nil
+}
+
+// CountFollowing returns how many users the given user follows
+func CountFollowing(userID string) (int64, error) {
+	var count int64
+	if err := database.DB.Model(&models.Follow{}).Where("follower_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count following: %w", err)
+	}
+	return count, nil
+}
@@ -11,7 +11,7 @@ import (
 // FindLikeByUserAndPost fetches a like by user and post from the database
 func FindLikeByUserAndPost(postID uuid.UUID, userID string) (*models.Like, error) {
 	var existingLike models.Like
-	err := database.DB.Where("post_id = ? AND user_id = ?", postID, userID).First(&existingLike).Error
+	err := database.DB.Where("post_id = ? AND user_id = ? AND comment_id IS NULL", postID, userID).First(&existingLike).Error
 	if err != nil {
 		return nil, err
 	}
@@ -41,3 +41,40 @@ func DeleteLikesByPostID(postID uuid.UUID) error {
 	}
 	return nil
 }
+
+// FindLikeByUserAndComment fetches a comment like by user and comment from the database
+func FindLikeByUserAndComment(commentID uuid.UUID, userID string) (*models.Like, error) {
+	var existingLike models.Like
+	err := database.DB.Where("comment_id = ? AND user_id = ?", commentID, userID).First(&existingLike).Error
+	if err != nil {
+		return nil, err
+	}
+	return &existingLike, nil
+}
+
+// FindLikedCommentIDs returns which of the given comments the user has liked
+func FindLikedCommentIDs(userID string, commentIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	liked := make(map[uuid.UUID]bool)
+	if len(commentIDs) == 0 {
+		return liked, nil
+	}
+
+	var likes []models.Like
+	if err := database.DB.Where("user_id = ? AND comment_id IN ?", userID, commentIDs).Find(&likes).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch comment likes: %w", err)
+	}
+
+	for _, like := range likes {
+		liked[*like.CommentID] = true
+	}
+	return liked, nil
+}
+
+// DeleteLikesByCommentID deletes all likes of a comment and of its replies
+func DeleteLikesByCommentID(commentID uuid.UUID) error {
+	if err := database.DB.Where("comment_id = ? OR comment_id IN (SELECT id FROM comments WHERE parent_id = ?)", commentID, commentID).
+		Delete(&models.Like{}).Error; err != nil {
+		return fmt.Errorf("could not delete likes for comment %v: %w", commentID, err)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -19,36 +20,109 @@ func SaveNotification(notification *models.Notification) error {
 	return nil
 }
 
-// MarkNotificationAsRead updates a notification to mark it as read
-func MarkNotificationAsRead(notificationID uuid.UUID) error {
-	notification := &models.Notification{}
-	if err := database.DB.First(notification, "id = ?", notificationID).Error; err != nil {
-		log.Println("Error finding notification to mark as read:", err)
-		return fmt.Errorf("notification not found: %w", err)
+// FindNotificationByID retrieves a notification of the user
+func FindNotificationByID(notificationID uuid.UUID, userID string) (*models.Notification, error) {
+	var notification models.Notification
+	if err := database.DB.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		return nil, err
 	}
-	notification.IsRead = true
-	if err := database.DB.Save(notification).Error; err != nil {
-		log.Println("Error updating notification as read:", err)
-		return fmt.Errorf("failed to update notification: %w", err)
+	return &notification, nil
+}
+
+// MarkNotificationAsRead marks a notification of the user as read, it returns gorm.ErrRecordNotFound
+// when the user has no such notification. updated_at is left alone so the notification keeps its place.
+func MarkNotificationAsRead(notificationID uuid.UUID, userID string) error {
+	result := database.DB.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", notificationID, userID).
+		UpdateColumn("is_read", true)
+	if result.Error != nil {
+		log.Println("Error updating notification as read:", result.Error)
+		return fmt.Errorf("failed to update notification: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
 	return nil
 }
 
-// DeleteNotification deletes a notification by its ID
-func DeleteNotification(notificationID uuid.UUID) error {
-	if err := database.DB.Where("id = ?", notificationID).Delete(&models.Notification{}).Error; err != nil {
-		log.Println("Error deleting notification:", err)
-		return fmt.Errorf("failed to delete notification: %w", err)
+// MarkNotificationsAsRead marks the given notifications of the user as read, IDs of other users'
+// notifications are ignored. It returns how many notifications were updated.
+func MarkNotificationsAsRead(userID string, notificationIDs []uuid.UUID) (int64, error) {
+	if len(notificationIDs) == 0 {
+		return 0, nil
+	}
+	result := database.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND id IN ? AND is_read = ?", userID, notificationIDs, false).
+		UpdateColumn("is_read", true)
+	if result.Error != nil {
+		log.Println("Error updating notifications as read:", result.Error)
+		return 0, fmt.Errorf("failed to update notifications: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// MarkAllNotificationsAsRead marks every unread notification of the user as read
+func MarkAllNotificationsAsRead(userID string) (int64, error) {
+	result := database.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		UpdateColumn("is_read", true)
+	if result.Error != nil {
+		log.Println("Error updating notifications as read:", result.Error)
+		return 0, fmt.Errorf("failed to update notifications: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// CountUnreadNotifications counts the unread notifications of the user
+func CountUnreadNotifications(userID string) (int64, error) {
+	var count int64
+	if err := database.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteNotification deletes a notification of the user, it returns gorm.ErrRecordNotFound
+// when the user has no such notification
+func DeleteNotification(notificationID uuid.UUID, userID string) error {
+	result := database.DB.Where("id = ? AND user_id = ?", notificationID, userID).Delete(&models.Notification{})
+	if result.Error != nil {
+		log.Println("Error deleting notification:", result.Error)
+		return fmt.Errorf("failed to delete notification: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
 	}
 	return nil
 }
 
-// FetchNotificationsByUserID retrieves notifications for a specific user
-func FetchNotificationsByUserID(userID string, limit int) ([]models.Notification, error) {
+// DeleteAllNotifications deletes every notification of the user and returns how many were removed
+func DeleteAllNotifications(userID string) (int64, error) {
+	result := database.DB.Where("user_id = ?", userID).Delete(&models.Notification{})
+	if result.Error != nil {
+		log.Println("Error deleting notifications:", result.Error)
+		return 0, fmt.Errorf("failed to delete notifications: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// FetchNotificationsByUserID retrieves notifications for a specific user, most recently updated first,
+// starting after the cursor. The list pages by updated_at, which moves when another actor joins a
+// notification: a notification updated while the user pages jumps to the top, the next pages don't
+// return it again and the first page shows it on the next refresh.
+func FetchNotificationsByUserID(userID string, limit int, cursor *utils.Cursor) ([]models.Notification, error) {
 	var notifications []models.Notification
 
-	if err := database.DB.Where("user_id = ?", userID).
+	query := database.DB.Where("user_id = ?", userID)
+	if cursor != nil {
+		query = query.Where("(updated_at, id) < (?, ?)", cursor.Time, cursor.ID)
+	}
+
+	if err := query.
 		Order("updated_at DESC").
+		Order("id DESC").
 		Limit(limit).
 		Find(&notifications).Error; err != nil {
 		log.Println("Error fetching notifications:", err)
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FindNotificationPreferences retrieves the notification preferences of the user, users who never
+// saved theirs get the defaults
+func FindNotificationPreferences(userID string) (*models.NotificationPreferences, error) {
+	var preferences models.NotificationPreferences
+	err := database.DB.Where("user_id = ?", userID).First(&preferences).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.NotificationPreferences{UserID: userID, Types: models.NotificationTypeSettings{}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notification preferences: %w", err)
+	}
+	return &preferences, nil
+}
+
+// SaveNotificationPreferences creates or replaces the notification preferences of the user
+func SaveNotificationPreferences(preferences *models.NotificationPreferences) error {
+	if err := database.DB.Save(preferences).Error; err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
+
+// MutePost stops the notifications about a post for the user, muting it again does nothing
+func MutePost(userID string, postID uuid.UUID) error {
+	mute := models.MutedPost{UserID: userID, PostID: postID}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&mute).Error; err != nil {
+		return fmt.Errorf("failed to mute post: %w", err)
+	}
+	return nil
+}
+
+// UnmutePost brings back the notifications about a post for the user
+func UnmutePost(userID string, postID uuid.UUID) error {
+	if err := database.DB.Where("user_id = ? AND post_id = ?", userID, postID).Delete(&models.MutedPost{}).Error; err != nil {
+		return fmt.Errorf("failed to unmute post: %w", err)
+	}
+	return nil
+}
+
+// IsPostMuted checks if the user muted the post
+func IsPostMuted(userID string, postID uuid.UUID) (bool, error) {
+	var count int64
+	if err := database.DB.Model(&models.MutedPost{}).
+		Where("user_id = ? AND post_id = ?", userID, postID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check muted post: %w", err)
+	}
+	return count > 0, nil
+}
+
+// DeleteMutedPostsByPostID removes every mute of a post
+func DeleteMutedPostsByPostID(postID uuid.UUID) error {
+	if err := database.DB.Where("post_id = ?", postID).Delete(&models.MutedPost{}).Error; err != nil {
+		return fmt.Errorf("failed to delete post mutes: %w", err)
+	}
+	return nil
+}
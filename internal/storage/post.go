@@ -5,9 +5,27 @@ import (
 
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+// withResharedPost loads the original post of reshares, originals the viewer can't see are left out
+func withResharedPost(viewerID string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Preload("ResharedPost", visibleTo(viewerID)).Preload("ResharedPost.Media", mediaInOrder)
+	}
+}
+
+// withMedia loads the media attachments of the posts
+func withMedia(db *gorm.DB) *gorm.DB {
+	return db.Preload("Media", mediaInOrder)
+}
+
+func mediaInOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("post_media.position")
+}
+
 // CreatePost creates a new post in the database
 func CreatePost(post models.Post) error {
 	// Add database logic here (e.g., GORM or raw SQL)
@@ -18,11 +36,24 @@ func CreatePost(post models.Post) error {
 	return nil
 }
 
-func GetPosts(limit int) ([]models.Post, error) {
+// newestPostsFirst orders posts from newest to oldest and starts after the cursor when one is given
+func newestPostsFirst(cursor *utils.Cursor) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if cursor != nil {
+			db = db.Where("(posts.created_at, posts.id) < (?, ?)", cursor.Time, cursor.ID)
+		}
+		return db.Order("posts.created_at DESC").Order("posts.id DESC")
+	}
+}
+
+// GetPosts retrieves the latest posts visible to the viewer, starting after the cursor
+func GetPosts(viewerID string, limit int, cursor *utils.Cursor) ([]models.Post, error) {
 	var posts []models.Post
 
 	// Fetch posts from the database, ordered by 'created_at' field in descending order
-	if err := database.DB.Order("created_at DESC").Limit(limit).Find(&posts).Error; err != nil {
+	if err := database.DB.Scopes(visibleTo(viewerID), withResharedPost(viewerID), withMedia, newestPostsFirst(cursor)).
+		Limit(limit).
+		Find(&posts).Error; err != nil {
 		return nil, err
 	}
 
@@ -37,13 +68,13 @@ func GetPostByID(id uuid.UUID) (*models.Post, error) {
 	return &post, nil
 }
 
-// GetPostsByUserID retrieves all posts made by a specific user, ordered by 'created_at'
-func GetPostsByUserID(userID string, limit int) ([]models.Post, error) {
+// GetPostsByUserID retrieves the posts made by a specific user that the viewer can see, ordered by 'created_at'
+func GetPostsByUserID(userID string, viewerID string, limit int, cursor *utils.Cursor) ([]models.Post, error) {
 	var posts []models.Post
 
 	// Fetch posts from the database where 'author_id' matches the userID
-	if err := database.DB.Where("author_id = ?", userID).
-		Order("created_at DESC").
+	if err := database.DB.Scopes(visibleTo(viewerID), withResharedPost(viewerID), withMedia, newestPostsFirst(cursor)).
+		Where("author_id = ?", userID).
 		Limit(limit).
 		Find(&posts).Error; err != nil {
 		return nil, err
@@ -51,9 +82,132 @@ func GetPostsByUserID(userID string, limit int) ([]models.Post, error) {
 
 	return posts, nil
 }
+
+// GetPostsByHashtag retrieves the posts carrying a normalized hashtag that the viewer can see, newest first
+func GetPostsByHashtag(hashtag string, viewerID string, limit int, cursor *utils.Cursor) ([]models.Post, error) {
+	var posts []models.Post
+
+	// The containment operator lets Postgres use the GIN index on hashtags
+	if err := database.DB.Scopes(visibleTo(viewerID), withResharedPost(viewerID), withMedia, newestPostsFirst(cursor)).
+		Where("posts.hashtags @> ARRAY[?]::text[]", hashtag).
+		Limit(limit).
+		Find(&posts).Error; err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
 func UpdatePost(post *models.Post) error {
-	return database.DB.Save(post).Error
+	return database.DB.Omit("ResharedPost", "Media").Save(post).Error
+}
+
+// UpdatePostWithRevision saves an edited post together with the revision holding its previous version.
+// When replaceMedia is set the attachments of the post are replaced by post.Media. Only the edited
+// columns are written so the counters are kept, and the link preview only when it was cleared, a
+// kept preview may be filled in by a background fetch in the meantime.
+func UpdatePostWithRevision(post *models.Post, revision *models.PostRevision, replaceMedia bool) error {
+	columns := []string{"body", "image_url", "image_variants", "share_state", "circle_ids", "hashtags", "mentioned_users", "edited_at"}
+	if post.LinkPreview == nil {
+		columns = append(columns, "link_preview")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(revision).Error; err != nil {
+			return fmt.Errorf("could not save post revision: %w", err)
+		}
+		if err := tx.Model(post).Select(columns).Updates(post).Error; err != nil {
+			return fmt.Errorf("could not update post: %w", err)
+		}
+		if !replaceMedia {
+			return nil
+		}
+		if err := tx.Where("post_id = ?", post.ID).Delete(&models.PostMedia{}).Error; err != nil {
+			return fmt.Errorf("could not delete post media: %w", err)
+		}
+		for i := range post.Media {
+			post.Media[i].PostID = post.ID
+		}
+		if len(post.Media) > 0 {
+			if err := tx.Create(&post.Media).Error; err != nil {
+				return fmt.Errorf("could not save post media: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetPostMedia retrieves the media attachments of a post in order
+func GetPostMedia(postID uuid.UUID) ([]models.PostMedia, error) {
+	var media []models.PostMedia
+	if err := database.DB.Where("post_id = ?", postID).Scopes(mediaInOrder).Find(&media).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch post media: %w", err)
+	}
+	return media, nil
+}
+
+// DeletePostMedia deletes the media attachments of a post
+func DeletePostMedia(postID uuid.UUID) error {
+	if err := database.DB.Where("post_id = ?", postID).Delete(&models.PostMedia{}).Error; err != nil {
+		return fmt.Errorf("could not delete media for post %v: %w", postID, err)
+	}
+	return nil
+}
+
+// GetPostRevisions retrieves the previous versions of a post, most recent first
+func GetPostRevisions(postID uuid.UUID) ([]models.PostRevision, error) {
+	var revisions []models.PostRevision
+	if err := database.DB.Where("post_id = ?", postID).Order("created_at DESC").Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch post revisions: %w", err)
+	}
+	return revisions, nil
 }
+
+// DeletePostRevisions deletes the edit history of a post
+func DeletePostRevisions(postID uuid.UUID) error {
+	if err := database.DB.Where("post_id = ?", postID).Delete(&models.PostRevision{}).Error; err != nil {
+		return fmt.Errorf("could not delete revisions for post %v: %w", postID, err)
+	}
+	return nil
+}
+
 func DeletePost(id uuid.UUID) error {
 	return database.DB.Delete(&models.Post{}, "id = ?", id).Error
 }
+
+// UpdatePostLikesCount adds delta to the likes counter of a post
+func UpdatePostLikesCount(postID uuid.UUID, delta int) error {
+	if err := database.DB.Model(&models.Post{}).Where("id = ?", postID).
+		UpdateColumn("likes_count", gorm.Expr("GREATEST(likes_count + ?, 0)", delta)).Error; err != nil {
+		return fmt.Errorf("failed to update likes counter: %w", err)
+	}
+	return nil
+}
+
+// UpdatePostResharesCount adds delta to the reshares counter of a post
+func UpdatePostResharesCount(postID uuid.UUID, delta int) error {
+	if err := database.DB.Model(&models.Post{}).Where("id = ?", postID).
+		UpdateColumn("reshares_count", gorm.Expr("GREATEST(reshares_count + ?, 0)", delta)).Error; err != nil {
+		return fmt.Errorf("failed to update reshares counter: %w", err)
+	}
+	return nil
+}
+
+// UpdatePostLinkPreview stores the link preview fetched after a post was saved, nothing changes
+// when the body was edited since
+func UpdatePostLinkPreview(postID uuid.UUID, body string, preview *models.LinkPreview) error {
+	if err := database.DB.Model(&models.Post{}).Where("id = ? AND body = ?", postID, body).
+		UpdateColumn("link_preview", preview).Error; err != nil {
+		return fmt.Errorf("failed to update link preview: %w", err)
+	}
+	return nil
+}
+
+// DetachReshares unlinks the reshares of a post so the original can be deleted
+func DetachReshares(postID uuid.UUID) error {
+	if err := database.DB.Model(&models.Post{}).Where("reshared_post_id = ?", postID).
+		UpdateColumn("reshared_post_id", nil).Error; err != nil {
+		return fmt.Errorf("could not detach reshares for post %v: %w", postID, err)
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/utils"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveNotificationWithPushes saves a notification and queues its pushes in one transaction, a
+// push is never queued for a notification that failed to save and the other way around
+func SaveNotificationWithPushes(notification *models.Notification, pushes []models.PushOutbox) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(notification).Error; err != nil {
+			return fmt.Errorf("could not save notification: %w", err)
+		}
+		return queuePushes(tx, notification.ID, pushes)
+	})
+}
+
+// QueuePushes queues the pushes of a notification that isn't kept, because the user turned off
+// the in-app notifications of its action type
+func QueuePushes(notificationID uuid.UUID, pushes []models.PushOutbox) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		return queuePushes(tx, notificationID, pushes)
+	})
+}
+
+func queuePushes(tx *gorm.DB, notificationID uuid.UUID, pushes []models.PushOutbox) error {
+	if len(pushes) == 0 {
+		return nil
+	}
+	for i := range pushes {
+		pushes[i].NotificationID = notificationID
+	}
+	if err := tx.Create(&pushes).Error; err != nil {
+		return fmt.Errorf("could not queue push notifications: %w", err)
+	}
+	return addDeliveryStats(tx, models.NotificationDeliveryStats{NotificationID: notificationID, Queued: int64(len(pushes))})
+}
+
+// ClaimDuePushes locks up to limit pending pushes whose attempt is due and pushes their next
+// attempt back by lease, so other workers skip them while they are being delivered. Pushes of a
+// worker that dies mid delivery are picked up again once the lease runs out.
+func ClaimDuePushes(limit int, lease time.Duration) ([]models.PushOutbox, error) {
+	var pushes []models.PushOutbox
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.PushStatusPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&pushes).Error; err != nil {
+			return err
+		}
+		if len(pushes) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, len(pushes))
+		for i, push := range pushes {
+			ids[i] = push.ID
+		}
+		return tx.Model(&models.PushOutbox{}).
+			Where("id IN ?", ids).
+			UpdateColumn("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim pushes: %w", err)
+	}
+	return pushes, nil
+}
+
+// MarkPushSent records the successful delivery of a push
+func MarkPushSent(push *models.PushOutbox) error {
+	now := time.Now()
+	push.Status = models.PushStatusSent
+	push.SentAt = &now
+	push.LastError = ""
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(push).Select("status", "attempts", "sent_at", "last_error", "provider", "ticket_id").Updates(push).Error; err != nil {
+			return err
+		}
+		return addDeliveryStats(tx, models.NotificationDeliveryStats{NotificationID: push.NotificationID, Sent: 1})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update push: %w", err)
+	}
+	return nil
+}
+
+// MarkPushFailed records a failed attempt, the push is either retried at its next attempt time
+// or dead when its status was set to dead. invalidToken tells the token was rejected for good.
+func MarkPushFailed(push *models.PushOutbox, invalidToken bool) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(push).Select("status", "attempts", "next_attempt_at", "last_error").Updates(push).Error; err != nil {
+			return err
+		}
+		if push.Status != models.PushStatusDead {
+			return nil
+		}
+		stats := models.NotificationDeliveryStats{NotificationID: push.NotificationID, Failed: 1}
+		if invalidToken {
+			stats.InvalidTokens = 1
+		}
+		return addDeliveryStats(tx, stats)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update push: %w", err)
+	}
+	return nil
+}
+
+// MarkPushSkipped records a push held back by the preferences of its user, reason tells why
+func MarkPushSkipped(push *models.PushOutbox, reason string) error {
+	push.Status = models.PushStatusSkipped
+	push.LastError = reason
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(push).Select("status", "last_error").Updates(push).Error; err != nil {
+			return err
+		}
+		return addDeliveryStats(tx, models.NotificationDeliveryStats{NotificationID: push.NotificationID, Skipped: 1})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update push: %w", err)
+	}
+	return nil
+}
+
+// FindPushesAwaitingReceipt retrieves the sent pushes of a provider whose receipt wasn't checked
+// yet, oldest first, sent before the given time and after the cursor
+func FindPushesAwaitingReceipt(provider string, sentBefore time.Time, limit int, cursor *utils.Cursor) ([]models.PushOutbox, error) {
+	var pushes []models.PushOutbox
+
+	query := database.DB.Where("status = ? AND provider = ? AND receipt_status = ? AND ticket_id <> ? AND sent_at <= ?",
+		models.PushStatusSent, provider, "", "", sentBefore)
+	if cursor != nil {
+		query = query.Where("(sent_at, id) > (?, ?)", cursor.Time, cursor.ID)
+	}
+
+	if err := query.
+		Order("sent_at").
+		Order("id").
+		Limit(limit).
+		Find(&pushes).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch pushes awaiting a receipt: %w", err)
+	}
+	return pushes, nil
+}
+
+// RecordPushReceipt saves the receipt of a sent push and counts it in the stats of its
+// notification. A receipt already recorded, by another instance for example, is left alone.
+func RecordPushReceipt(push *models.PushOutbox, receiptStatus string, receiptError string, invalidToken bool) error {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PushOutbox{}).
+			Where("id = ? AND receipt_status = ?", push.ID, "").
+			Updates(map[string]interface{}{"receipt_status": receiptStatus, "receipt_error": receiptError})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		push.ReceiptStatus, push.ReceiptError = receiptStatus, receiptError
+
+		stats := models.NotificationDeliveryStats{NotificationID: push.NotificationID}
+		switch receiptStatus {
+		case models.PushReceiptDelivered:
+			stats.Delivered = 1
+		case models.PushReceiptFailed:
+			stats.Failed = 1
+		}
+		if invalidToken {
+			stats.InvalidTokens = 1
+		}
+		return addDeliveryStats(tx, stats)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record push receipt: %w", err)
+	}
+	return nil
+}
+
+// addDeliveryStats adds the counters of stats to the delivery stats of its notification
+func addDeliveryStats(tx *gorm.DB, stats models.NotificationDeliveryStats) error {
+	err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "notification_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"queued":         gorm.Expr("notification_delivery_stats.queued + EXCLUDED.queued"),
+			"sent":           gorm.Expr("notification_delivery_stats.sent + EXCLUDED.sent"),
+			"delivered":      gorm.Expr("notification_delivery_stats.delivered + EXCLUDED.delivered"),
+			"failed":         gorm.Expr("notification_delivery_stats.failed + EXCLUDED.failed"),
+			"invalid_tokens": gorm.Expr("notification_delivery_stats.invalid_tokens + EXCLUDED.invalid_tokens"),
+			"skipped":        gorm.Expr("notification_delivery_stats.skipped + EXCLUDED.skipped"),
+			"updated_at":     gorm.Expr("EXCLUDED.updated_at"),
+		}),
+	}).Create(&stats).Error
+	if err != nil {
+		return fmt.Errorf("could not update delivery stats: %w", err)
+	}
+	return nil
+}
+
+// FindDeliveryStats retrieves the delivery stats of a notification, it returns
+// gorm.ErrRecordNotFound when no push was ever queued for it
+func FindDeliveryStats(notificationID uuid.UUID) (*models.NotificationDeliveryStats, error) {
+	var stats models.NotificationDeliveryStats
+	if err := database.DB.Where("notification_id = ?", notificationID).First(&stats).Error; err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// FetchPushOutbox retrieves the pushes with the given status, most recently updated first,
+// starting after the cursor
+func FetchPushOutbox(status string, limit int, cursor *utils.Cursor) ([]models.PushOutbox, error) {
+	var pushes []models.PushOutbox
+
+	query := database.DB.Where("status = ?", status)
+	if cursor != nil {
+		query = query.Where("(updated_at, id) < (?, ?)", cursor.Time, cursor.ID)
+	}
+
+	if err := query.
+		Order("updated_at DESC").
+		Order("id DESC").
+		Limit(limit).
+		Find(&pushes).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch pushes: %w", err)
+	}
+	return pushes, nil
+}
+
+// CountPushOutboxByStatus counts the pushes of each status
+func CountPushOutboxByStatus() (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	if err := database.DB.Model(&models.PushOutbox{}).
+		Select("status, COUNT(*) AS count").
+		Group("status").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count pushes: %w", err)
+	}
+
+	counts := map[string]int64{
+		models.PushStatusPending: 0,
+		models.PushStatusSent:    0,
+		models.PushStatusDead:    0,
+		models.PushStatusSkipped: 0,
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// RequeueDeadPush puts a dead push back in the queue with a fresh set of attempts, it returns
+// gorm.ErrRecordNotFound when there is no dead push with this ID
+func RequeueDeadPush(pushID uuid.UUID) error {
+	result := database.DB.Model(&models.PushOutbox{}).
+		Where("id = ? AND status = ?", pushID, models.PushStatusDead).
+		Updates(map[string]interface{}{
+			"status":          models.PushStatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to requeue push: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteFinishedPushesBefore removes the sent and skipped pushes last updated before the given time
+func DeleteFinishedPushesBefore(before time.Time) (int64, error) {
+	result := database.DB.Where("status IN ? AND updated_at < ?", []string{models.PushStatusSent, models.PushStatusSkipped}, before).
+		Delete(&models.PushOutbox{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete finished pushes: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -42,3 +42,30 @@ func UpdateUser(user models.User) error {
 	}
 	return nil
 }
+
+// FindUsersByIDs retrieves the users with the given IDs, unknown IDs are skipped
+func FindUsersByIDs(ids []string) ([]models.User, error) {
+	var users []models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	if err := database.DB.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// FindUsersByMentionHandles retrieves the users whose lower-cased username without spaces
+// matches one of the handles
+func FindUsersByMentionHandles(handles []string) ([]models.User, error) {
+	var users []models.User
+	if len(handles) == 0 {
+		return users, nil
+	}
+
+	if err := database.DB.Where("LOWER(REPLACE(username, ' ', '')) IN ?", handles).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
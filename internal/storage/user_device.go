@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SaveUserDevice registers the device of its token or refreshes it, a token registered by another
+// user is moved to this one. Empty details keep the stored ones and the device is reloaded with
+// its stored ID.
+func SaveUserDevice(device *models.UserDevice) error {
+	keepIfEmpty := func(column string) clause.Expr {
+		return gorm.Expr("COALESCE(NULLIF(EXCLUDED." + column + ", ''), user_devices." + column + ")")
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "token"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"user_id":      gorm.Expr("EXCLUDED.user_id"),
+				"platform":     keepIfEmpty("platform"),
+				"app_version":  keepIfEmpty("app_version"),
+				"locale":       keepIfEmpty("locale"),
+				"last_seen_at": gorm.Expr("EXCLUDED.last_seen_at"),
+				"updated_at":   gorm.Expr("EXCLUDED.updated_at"),
+			}),
+		}).Create(device).Error; err != nil {
+			return err
+		}
+		return tx.Where("token = ?", device.Token).First(device).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save device: %w", err)
+	}
+	return nil
+}
+
+// FindUserDevices retrieves the devices of the user, most recently seen first
+func FindUserDevices(userID string) ([]models.UserDevice, error) {
+	var devices []models.UserDevice
+	if err := database.DB.Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&devices).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch devices: %w", err)
+	}
+	return devices, nil
+}
+
+// FindActiveUserDevices retrieves the devices of the user seen since the given time
+func FindActiveUserDevices(userID string, seenSince time.Time) ([]models.UserDevice, error) {
+	var devices []models.UserDevice
+	if err := database.DB.Where("user_id = ? AND last_seen_at >= ?", userID, seenSince).Find(&devices).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch active devices: %w", err)
+	}
+	return devices, nil
+}
+
+// DeleteUserDevice unregisters a device of the user, it returns gorm.ErrRecordNotFound when the
+// user has no such device
+func DeleteUserDevice(deviceID uuid.UUID, userID string) error {
+	result := database.DB.Where("id = ? AND user_id = ?", deviceID, userID).Delete(&models.UserDevice{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete device: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteUserDeviceByToken unregisters the device of a token if it still belongs to the user
+func DeleteUserDeviceByToken(userID string, token string) error {
+	if err := database.DB.Where("user_id = ? AND token = ?", userID, token).Delete(&models.UserDevice{}).Error; err != nil {
+		return fmt.Errorf("failed to delete device: %w", err)
+	}
+	return nil
+}
+
+// PruneUserDevices keeps the given number of most recently seen devices of the user and deletes the others
+func PruneUserDevices(userID string, keep int) error {
+	recent := database.DB.Model(&models.UserDevice{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("last_seen_at DESC").
+		Limit(keep)
+	if err := database.DB.Where("user_id = ? AND id NOT IN (?)", userID, recent).Delete(&models.UserDevice{}).Error; err != nil {
+		return fmt.Errorf("failed to prune devices: %w", err)
+	}
+	return nil
+}
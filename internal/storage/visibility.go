@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"errors"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// postVisibilityCondition is the single place where post visibility is decided:
+//   - the author always sees their own posts
+//   - Public posts are visible to everyone
+//   - Private posts are visible to the author's followers
+//   - Circles posts are visible to the members of one of the target circles
+//   - Only me posts, and any unknown share state, are visible to the author only
+const postVisibilityCondition = `(posts.author_id = @viewer
+	OR posts.share_state = @public
+	OR (posts.share_state = @private AND EXISTS (
+		SELECT 1 FROM follows WHERE follows.follower_id = @viewer AND follows.following_id::text = posts.author_id))
+	OR (posts.share_state = @circles AND EXISTS (
+		SELECT 1 FROM circle_members WHERE circle_members.member_id = @viewer AND circle_members.circle_id::text = ANY(posts.circle_ids))))`
+
+// visibleTo limits a posts query to the posts the viewer is allowed to see
+func visibleTo(viewerID string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(postVisibilityCondition, map[string]interface{}{
+			"viewer":  viewerID,
+			"public":  models.ShareStatePublic,
+			"private": models.ShareStatePrivate,
+			"circles": models.ShareStateCircles,
+		})
+	}
+}
+
+// CanViewPost reports whether the viewer is allowed to see the post
+func CanViewPost(postID uuid.UUID, viewerID string) (bool, error) {
+	_, err := GetPostByIDForViewer(postID, viewerID)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
+// GetPostByIDForViewer retrieves a post only if the viewer is allowed to see it,
+// otherwise it returns gorm.ErrRecordNotFound
+func GetPostByIDForViewer(id uuid.UUID, viewerID string) (*models.Post, error) {
+	var post models.Post
+	if err := database.DB.Scopes(visibleTo(viewerID)).First(&post, "posts.id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// GetPostDetailsForViewer retrieves a post the viewer is allowed to see together with
+// the original post when it is a reshare, otherwise it returns gorm.ErrRecordNotFound
+func GetPostDetailsForViewer(id uuid.UUID, viewerID string) (*models.Post, error) {
+	var post models.Post
+	if err := database.DB.Scopes(visibleTo(viewerID), withResharedPost(viewerID), withMedia).
+		First(&post, "posts.id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
@@ -1,33 +1,31 @@
 package utils
 
 import (
-	"fmt"
+	"errors"
 	"time"
 
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
-	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models/requestModels"
 	"github.com/google/uuid"
 )
 
+// ErrEmptyComment is returned when a comment has no content
+var ErrEmptyComment = errors.New("comment content cannot be empty")
+
 func ValidateCommentContent(content string) error {
 	if content == "" {
-		return fmt.Errorf("comment content cannot be empty")
+		return ErrEmptyComment
 	}
 	return nil
 }
 
-func CreateNewComment(postID uuid.UUID, commentRequestBody requestModels.CreateCommentRequestBody, user *models.User) (*models.Comment, error) {
-	var mentionedUser models.MentionedUser
-	if len(commentRequestBody.MentionedUsers) > 0 && commentRequestBody.MentionedUsers[0].UserID != "" {
-		mentionedUser = commentRequestBody.MentionedUsers[0]
-	}
-
+// CreateNewComment builds a comment by the user keeping every mentioned user
+func CreateNewComment(postID uuid.UUID, content string, mentionedUsers models.MentionedUserArray, user *models.User) (*models.Comment, error) {
 	newComment := &models.Comment{
 		ID:             uuid.New(),
 		PostID:         postID,
 		UserID:         user.ID,
-		Content:        commentRequestBody.Content,
-		MentionedUsers: []models.MentionedUser{mentionedUser},
+		Content:        content,
+		MentionedUsers: mentionedUsers,
 		AuthorName:     user.Username,
 		AuthorAvatar:   user.ProfileAvatar,
 		CreatedAt:      time.Now(),
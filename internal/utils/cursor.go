@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor is an opaque keyset position used to page through lists ordered by a timestamp and the row ID
+type Cursor struct {
+	Time  time.Time `json:"t"`
+	ID    uuid.UUID `json:"id"`
+	Score float64   `json:"s,omitempty"` // Only set for ranked lists, Time then holds the ranking snapshot time
+}
+
+// EncodeCursor builds the opaque cursor string sent to the client
+func EncodeCursor(t time.Time, id uuid.UUID) string {
+	payload, err := json.Marshal(Cursor{Time: t, ID: id})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// EncodeScoredCursor builds the opaque cursor string of a ranked list
+func EncodeScoredCursor(t time.Time, score float64, id uuid.UUID) string {
+	payload, err := json.Marshal(Cursor{Time: t, ID: id, Score: score})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// DecodeCursor parses a cursor sent by the client, an empty string means the first page
+func DecodeCursor(value string) (*Cursor, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if cursor.ID == uuid.Nil || cursor.Time.IsZero() {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := uuid.MustParse("8f14e45f-ceea-4e67-a5d2-9b2f1a3c7d10")
+	baghdad := time.FixedZone("Baghdad", 3*60*60)
+
+	tests := []struct {
+		name  string
+		value string
+		time  time.Time
+		score float64
+	}{
+		{"UTC", EncodeCursor(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), id), time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), 0},
+		{"nanoseconds", EncodeCursor(time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC), id), time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC), 0},
+		{"other zone", EncodeCursor(time.Date(2024, 3, 1, 15, 0, 0, 0, baghdad), id), time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), 0},
+		{"score", EncodeScoredCursor(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), 4.1875, id), time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), 4.1875},
+		{"long score", EncodeScoredCursor(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), 3.0000000000000004, id), time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), 3.0000000000000004},
+		{"negative score", EncodeScoredCursor(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), -0.5, id), time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), -0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := DecodeCursor(tt.value)
+			if err != nil {
+				t.Fatalf("DecodeCursor(%q): %v", tt.value, err)
+			}
+			if !cursor.Time.Equal(tt.time) || cursor.ID != id || cursor.Score != tt.score {
+				t.Errorf("cursor = %+v, want time %s, ID %s and score %v", cursor, tt.time, id, tt.score)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorEmpty(t *testing.T) {
+	cursor, err := DecodeCursor("")
+	if cursor != nil || err != nil {
+		t.Errorf("DecodeCursor(\"\") = %+v, %v, want the first page", cursor, err)
+	}
+}
+
+func TestDecodeCursorMalformed(t *testing.T) {
+	encode := func(payload string) string { return base64.RawURLEncoding.EncodeToString([]byte(payload)) }
+	valid := EncodeCursor(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), uuid.MustParse("8f14e45f-ceea-4e67-a5d2-9b2f1a3c7d10"))
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"not base64", "not a cursor!"},
+		{"truncated", valid[:len(valid)-4]},
+		{"not JSON", encode("hello")},
+		{"JSON array", encode(`["2024-03-01T12:00:00Z"]`)},
+		{"empty object", encode(`{}`)},
+		{"missing ID", encode(`{"t":"2024-03-01T12:00:00Z"}`)},
+		{"nil ID", encode(`{"t":"2024-03-01T12:00:00Z","id":"00000000-0000-0000-0000-000000000000"}`)},
+		{"invalid ID", encode(`{"t":"2024-03-01T12:00:00Z","id":"42"}`)},
+		{"missing time", encode(`{"id":"8f14e45f-ceea-4e67-a5d2-9b2f1a3c7d10"}`)},
+		{"invalid time", encode(`{"t":"yesterday","id":"8f14e45f-ceea-4e67-a5d2-9b2f1a3c7d10"}`)},
+		{"invalid score", encode(`{"t":"2024-03-01T12:00:00Z","id":"8f14e45f-ceea-4e67-a5d2-9b2f1a3c7d10","s":"high"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cursor, err := DecodeCursor(tt.value); err == nil {
+				t.Errorf("DecodeCursor(%q) = %+v, want an error", tt.value, cursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// hashtagPattern matches a '#' followed by letters, marks, digits or underscores. The '#' must not
+// be glued to a word or a path so URL fragments like example.com/#top are not taken as hashtags.
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{M}\p{N}_&/])#([\p{L}\p{M}\p{N}_]+)`)
+
+// maxHashtagLength is the longest hashtag kept, in runes
+const maxHashtagLength = 100
+
+// ExtractHashtags returns the normalized, de-duplicated hashtags of a text in order of appearance
+func ExtractHashtags(text string) []string {
+	seen := make(map[string]bool)
+	hashtags := []string{}
+
+	for _, match := range hashtagPattern.FindAllStringSubmatch(text, -1) {
+		hashtag := NormalizeHashtag(match[1])
+		if hashtag == "" || seen[hashtag] || !hasLetter(hashtag) || len([]rune(hashtag)) > maxHashtagLength {
+			continue
+		}
+		seen[hashtag] = true
+		hashtags = append(hashtags, hashtag)
+	}
+
+	return hashtags
+}
+
+// NormalizeHashtag lower-cases a hashtag and folds Arabic letter variants so that
+// spellings people use interchangeably end up as the same tag
+func NormalizeHashtag(hashtag string) string {
+	hashtag = strings.TrimPrefix(strings.TrimSpace(hashtag), "#")
+
+	var builder strings.Builder
+	for _, r := range strings.ToLower(hashtag) {
+		switch {
+		case r >= 'ً' && r <= 'ٟ', r == 'ٰ', r == 'ـ':
+			// Drop tashkeel (harakat), superscript alef and tatweel
+			continue
+		case r == 'أ', r == 'إ', r == 'آ', r == 'ٱ':
+			r = 'ا'
+		case r == 'ى', r == 'ئ', r == 'ی':
+			r = 'ي'
+		case r == 'ؤ':
+			r = 'و'
+		case r == 'ة':
+			r = 'ه'
+		case r == 'ک':
+			r = 'ك'
+		case r >= '٠' && r <= '٩':
+			// Arabic-Indic digits
+			r = '0' + (r - '٠')
+		case r >= '۰' && r <= '۹':
+			// Extended Arabic-Indic (Persian) digits
+			r = '0' + (r - '۰')
+		}
+		builder.WriteRune(r)
+	}
+
+	return builder.String()
+}
+
+// hasLetter reports whether the hashtag has at least one letter, "#2024" alone is not a hashtag
+func hasLetter(hashtag string) bool {
+	for _, r := range hashtag {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"none", "Hello world", []string{}},
+		{"order of appearance", "#go and #rust then #zig", []string{"go", "rust", "zig"}},
+		{"case", "#GoLang #golang #GOLANG", []string{"golang"}},
+		{"duplicates keep the first place", "#b #a #b #a", []string{"b", "a"}},
+		{"start of lines", "#first\n#second", []string{"first", "second"}},
+		{"trailing punctuation", "#go, #rust! (#zig) #c++ #end.", []string{"go", "rust", "zig", "c", "end"}},
+		{"underscores and digits", "#snake_case #web3", []string{"snake_case", "web3"}},
+		{"digits only", "#2024 #123 #2024goals", []string{"2024goals"}},
+		{"glued to a word", "foo#bar", []string{}},
+		{"URL fragment", "see example.com/#top", []string{}},
+		{"HTML entity", "it&#x27;s", []string{}},
+		{"empty tag", "# #", []string{}},
+		{"emoji", "#🔥 #fire🔥", []string{"fire"}},
+		{"Latin accents", "#Café #ÉTÉ", []string{"café", "été"}},
+		{"combining marks", "#cafe\u0301", []string{"cafe\u0301"}},
+		{"other scripts", "#日本 #Ελλάδα #Москва", []string{"日本", "ελλάδα", "москва"}},
+		{"Arabic", "#بغداد", []string{"بغداد"}},
+		{"Arabic variants", "#أحمد #احمد #إحمد", []string{"احمد"}},
+		{"Arabic diacritics", "#مَدْرَسَة #مدرسة", []string{"مدرسه"}},
+		{"Arabic-Indic digits", "#العراق٢٠٢٤ #العراق2024", []string{"العراق2024"}},
+		{"longest tag", "#" + strings.Repeat("a", 100), []string{strings.Repeat("a", 100)}},
+		{"too long", "#" + strings.Repeat("a", 101), []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractHashtags(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("ExtractHashtags(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeHashtag(t *testing.T) {
+	tests := []struct {
+		hashtag string
+		want    string
+	}{
+		{"GoLang", "golang"},
+		{"#GoLang", "golang"},
+		{"  #go  ", "go"},
+		{"ÉTÉ", "été"},
+		{"ΕΛΛΆΔΑ", "ελλάδα"},
+		{"أحمد", "احمد"},
+		{"إسلام", "اسلام"},
+		{"آمنة", "امنه"},
+		{"ٱلقدس", "القدس"},
+		{"مصطفى", "مصطفي"},
+		{"شاطئ", "شاطي"},
+		{"مسؤول", "مسوول"},
+		{"کتاب", "كتاب"},
+		{"فـــن", "فن"},
+		{"عِلْمٌ", "علم"},
+		{"الرحمٰن", "الرحمن"},
+		{"٢٠٢٤", "2024"},
+		{"۱۴۰۳", "1403"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hashtag, func(t *testing.T) {
+			if got := NormalizeHashtag(tt.hashtag); got != tt.want {
+				t.Errorf("NormalizeHashtag(%q) = %q, want %q", tt.hashtag, got, tt.want)
+			}
+		})
+	}
+}
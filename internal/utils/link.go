@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"'` + "`" + `]+`)
+
+// ExtractFirstLink returns the first http or https URL written in the text, or an empty string
+func ExtractFirstLink(text string) string {
+	for _, match := range linkPattern.FindAllString(text, -1) {
+		// Punctuation ending a sentence is not part of the link
+		link := strings.TrimRight(match, ".,;:!?")
+		if strings.HasSuffix(link, ")") && !strings.Contains(link, "(") {
+			link = strings.TrimSuffix(link, ")")
+		}
+
+		if parsed, err := url.Parse(link); err == nil && parsed.Host != "" {
+			return link
+		}
+	}
+	return ""
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionPattern matches an '@' followed by a handle. The '@' must not be glued to a word
+// so e-mail addresses like someone@example.com are not taken as mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{M}\p{N}_.@])@([\p{L}\p{M}\p{N}_.]+)`)
+
+// ExtractMentionHandles returns the de-duplicated, normalized @handles of a text in order of appearance
+func ExtractMentionHandles(text string) []string {
+	seen := make(map[string]bool)
+	handles := []string{}
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		handle := NormalizeMentionHandle(strings.TrimRight(match[1], "."))
+		if handle == "" || seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		handles = append(handles, handle)
+	}
+
+	return handles
+}
+
+// NormalizeMentionHandle turns a username into the handle used to mention it:
+// lower-cased with the spaces removed, so "Sajjad Ali" is mentioned as @SajjadAli
+func NormalizeMentionHandle(username string) string {
+	return strings.ToLower(strings.Join(strings.Fields(strings.TrimPrefix(username, "@")), ""))
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractMentionHandles(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"none", "Hello world", []string{}},
+		{"start of text", "@sara hi", []string{"sara"}},
+		{"start of lines", "hi\n@sara\n@ali", []string{"sara", "ali"}},
+		{"order of appearance", "@ali @sara @omar", []string{"ali", "sara", "omar"}},
+		{"case and duplicates", "@Sara @sara @SARA", []string{"sara"}},
+		{"end of sentence", "Thanks @Sara.", []string{"sara"}},
+		{"ellipsis", "@sara...", []string{"sara"}},
+		{"dots inside", "@sara.ali", []string{"sara.ali"}},
+		{"commas and exclamation marks", "@sara, @ali!", []string{"sara", "ali"}},
+		{"parentheses", "(@sara) [@ali]", []string{"sara", "ali"}},
+		{"quotes", `"@sara" '@ali'`, []string{"sara", "ali"}},
+		{"possessive", "@sara's post", []string{"sara"}},
+		{"colon", "@sara: hi", []string{"sara"}},
+		{"underscores", "@_sara_ali_", []string{"_sara_ali_"}},
+		{"Unicode", "@سارة @Ωmega", []string{"سارة", "ωmega"}},
+		{"email address", "mail sara@example.com", []string{}},
+		{"email address with a dot", "sara.ali@example.com", []string{}},
+		{"email address after punctuation", "(sara@example.com)", []string{}},
+		{"double at", "@@sara", []string{}},
+		{"glued to a word", "hi@sara", []string{}},
+		{"at alone", "@ @. @!", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractMentionHandles(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("ExtractMentionHandles(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeMentionHandle(t *testing.T) {
+	tests := []struct {
+		username string
+		want     string
+	}{
+		{"Sara", "sara"},
+		{"@Sara", "sara"},
+		{"Sajjad Ali", "sajjadali"},
+		{"  Sara \t Ali  ", "saraali"},
+		{"سارة علي", "سارةعلي"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			if got := NormalizeMentionHandle(tt.username); got != tt.want {
+				t.Errorf("NormalizeMentionHandle(%q) = %q, want %q", tt.username, got, tt.want)
+			}
+		})
+	}
+}
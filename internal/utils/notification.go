@@ -1,13 +1,12 @@
 package utils
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"log"
-	"net/http"
+	"strings"
 
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/push"
 )
 
 // MessageTemplates holds the message format for each action type in both Arabic and English
@@ -24,6 +23,31 @@ var MessageTemplates = map[string]map[string]string{
 		"ar": "أشار إليك %s: %s",
 		"en": "%s mentioned you: %s",
 	},
+	"comment_like": {
+		"ar": "أبدى %s إعجاباً بتعليقك: %s",
+		"en": "%s liked your comment: %s",
+	},
+	"reply": {
+		"ar": "رد %s على تعليقك: %s",
+		"en": "%s replied to your comment: %s",
+	},
+	"reshare": {
+		"ar": "أعاد %s مشاركة منشورك: %s",
+		"en": "%s reshared your post: %s",
+	},
+}
+
+// combinedMessageTemplates tell both actions of a comment or a reply that also mentions the user,
+// they are not action types of their own
+var combinedMessageTemplates = map[string]map[string]string{
+	"comment_mention": {
+		"ar": "علق %s على مشاركتك وأشار إليك: %s",
+		"en": "%s commented on your post and mentioned you: %s",
+	},
+	"reply_mention": {
+		"ar": "رد %s على تعليقك وأشار إليك: %s",
+		"en": "%s replied to your comment and mentioned you: %s",
+	},
 }
 
 // createNotificationMessage generates the notification message based on actions
@@ -34,6 +58,14 @@ func CreateNotificationMessage(notification models.Notification, lang string) st
 
 	lastActionType, lastActor := CollectLastActionType(notification)
 
+	// A mention saved together with a comment or a reply uses the template telling both
+	if count := len(notification.ActionType); lastActionType == "mention" && count > 1 {
+		combined := notification.ActionType[count-2] + "_mention"
+		if _, ok := combinedMessageTemplates[combined]; ok {
+			lastActionType = combined
+		}
+	}
+
 	return BuildNotificationMessage(lastActionType, lastActor, notification, lang)
 }
 
@@ -63,7 +95,11 @@ func BuildNotificationMessage(lastActionType, lastActor string, notification mod
 	}
 
 	// Choose the correct message template based on the action type and language
-	if template, ok := MessageTemplates[lastActionType]; ok {
+	template, ok := MessageTemplates[lastActionType]
+	if !ok {
+		template, ok = combinedMessageTemplates[lastActionType]
+	}
+	if ok {
 		// Add "و آخرون" or "and others" if there are multiple actors
 		if len(notification.Actors) > 1 {
 			if lang == "ar" {
@@ -82,54 +118,38 @@ func BuildNotificationMessage(lastActionType, lastActor string, notification mod
 	return message
 }
 
-// SendPushNotification sends a push notification to the user
-func SendPushNotification(notifyUser *models.User, notification *models.Notification) error {
-	// Fetch the user's Expo push token from storage
-
-	if notifyUser.PushToken == "" {
-		return fmt.Errorf("no push token found for user %s", notifyUser)
+// NotificationLanguage picks the language of the messages for a locale such as "ar-IQ", the
+// fallback is used for locales without translated messages
+func NotificationLanguage(locale string, fallback string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(locale), "-")
+	lang, _, _ = strings.Cut(lang, "_")
+	if _, ok := MessageTemplates["like"][lang]; ok {
+		return lang
 	}
+	return fallback
+}
 
-	// Construct the push notification message
-	message := CreateNotificationMessage(*notification, notifyUser.UserLang)
-
-	// Prepare the payload for the Expo Push Notification API
-	payload := map[string]interface{}{
-		"to":    notifyUser.PushToken,
-		"title": notification.Actors[len(notification.Actors)-1].Name, // Customize the title as needed
-		"body":  message,
-		"data":  map[string]string{"reference_id": notification.ReferenceID.String()}, // Pass the parsed refID
-	}
+// NewPushMessage builds the push of a notification for a device token in the given language
+func NewPushMessage(token string, lang string, notification *models.Notification) push.Message {
+	_, lastActor := CollectLastActionType(*notification)
 
-	// Send the notification to the Expo Push Notification service
-	response, err := sendToExpoPushAPI(payload)
-	if err != nil {
-		return fmt.Errorf("failed to send notification to Expo: %w", err)
+	return push.Message{
+		Token: token,
+		Title: lastActor,
+		Body:  CreateNotificationMessage(*notification, lang),
+		Data:  map[string]string{"reference_id": notification.ReferenceID.String()},
 	}
-
-	// Log the response from the Expo Push Notification service
-	log.Println("Response from Expo Push API:", response)
-
-	return nil
 }
 
-func sendToExpoPushAPI(payload map[string]interface{}) (string, error) {
-	url := "https://exp.host/--/api/v2/push/send"
-
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return "", err
+// SendPushNotification delivers a push with the provider of its token
+func SendPushNotification(ctx context.Context, message push.Message) (push.Result, error) {
+	if push.Sender == nil {
+		return push.Result{}, fmt.Errorf("push notifications are not set up")
 	}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(payloadBytes))
+	result, err := push.Sender.Send(ctx, message)
 	if err != nil {
-		return "", err
+		return push.Result{}, fmt.Errorf("failed to send push notification: %w", err)
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("received non-200 response: %s", resp.Status)
-	}
-
-	return "Notification sent successfully!", nil
+	return result, nil
 }
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/models"
+)
+
+func TestCreateNotificationMessage(t *testing.T) {
+	sara := models.Actor{ID: "1", Name: "Sara"}
+	ali := models.Actor{ID: "2", Name: "Ali"}
+
+	tests := []struct {
+		name        string
+		actors      models.ActorArray
+		actionTypes models.ActionTypeArray
+		lang        string
+		want        string
+	}{
+		{"like", models.ActorArray{sara}, models.ActionTypeArray{"like"}, "en", "\u200FSara liked your post: Hello"},
+		{"mention", models.ActorArray{sara}, models.ActionTypeArray{"mention"}, "en", "\u200FSara mentioned you: Hello"},
+		{"comment with a mention", models.ActorArray{sara}, models.ActionTypeArray{"comment", "mention"}, "en",
+			"\u200FSara commented on your post and mentioned you: Hello"},
+		{"reply with a mention", models.ActorArray{sara}, models.ActionTypeArray{"reply", "mention"}, "en",
+			"\u200FSara replied to your comment and mentioned you: Hello"},
+		{"mention after a like", models.ActorArray{ali, sara}, models.ActionTypeArray{"like", "mention"}, "en",
+			"Sara and others mentioned you: Hello"},
+		{"comment after a mention", models.ActorArray{sara}, models.ActionTypeArray{"mention", "comment"}, "en",
+			"\u200FSara commented on your post: Hello"},
+		{"comment with a mention in Arabic", models.ActorArray{sara}, models.ActionTypeArray{"comment", "mention"}, "ar",
+			"علق \u200FSara على مشاركتك وأشار إليك: Hello"},
+		{"no language", models.ActorArray{sara}, models.ActionTypeArray{"reshare"}, "", "\u200FSara reshared your post: Hello"},
+		{"no actors", nil, models.ActionTypeArray{"like"}, "en", ""},
+		{"unknown action", models.ActorArray{sara}, models.ActionTypeArray{"poke"}, "en", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := models.Notification{Actors: tt.actors, ActionType: tt.actionTypes, ReferenceContent: "Hello"}
+			if got := CreateNotificationMessage(notification, tt.lang); got != tt.want {
+				t.Errorf("CreateNotificationMessage = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	_ "time/tzdata" // Quiet hours use the timezone of each user, even on images without tzdata
 
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/database"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/media"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/push"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/realtime"
 	"github.com/Sajjad-iq/google_plus_react_native_go/internal/routes"
+	"github.com/Sajjad-iq/google_plus_react_native_go/internal/services"
 	"github.com/Sajjad-iq/google_plus_react_native_go/middleware"
 	jwtware "github.com/gofiber/contrib/jwt"
 	"github.com/gofiber/fiber/v2"
@@ -24,8 +30,20 @@ func main() {
 	// Connect to the database
 	database.Connect()
 
+	// Receive the notification events of every instance for the streaming connections
+	realtime.Setup(context.Background(), database.DSN())
+
+	// Choose the push providers for the device tokens and deliver the queued pushes
+	push.Setup()
+	services.StartPushWorker(context.Background())
+
 	// Set up the Fiber app
-	app := fiber.New()
+	app := fiber.New(fiber.Config{
+		// Bodies over the default limit are streamed instead of held in memory, only the post
+		// uploads are allowed to be that large
+		StreamRequestBody: true,
+	})
+	app.Use(middleware.BodyLimit(fiber.DefaultBodyLimit, routes.IsPostUpload))
 
 	// Configure CORS
 	app.Use(cors.New(cors.Config{
@@ -39,8 +57,11 @@ func main() {
 
 	// Routes that don’t require authentication (like auth routes)
 	routes.AuthRoutesSetup(app)
-	// Serve static files (uploads)
-	app.Static("/uploads", "./uploads")
+	// Set up the media storage, files of the local store are served by the app
+	media.Setup()
+	if localStore, ok := media.Store.(*media.LocalStore); ok && localStore.PublicURL == "" {
+		app.Static(media.LocalURLPrefix, localStore.Dir)
+	}
 
 	// Use JWT middleware for protected routes
 	// Only apply JWT for routes that need it
@@ -53,6 +74,8 @@ func main() {
 	// Protected routes
 	routes.PostsRoutesSetup(app)
 	routes.UsersRoutesSetup(app)
+	routes.CirclesRoutesSetup(app)
+	routes.AdminRoutesSetup(app)
 
 	// Start the server
 	log.Fatal(app.Listen(":4000"))
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// BodyLimit rejects requests with a body larger than limit. The server streams the bodies over
+// its default limit instead of loading them in memory, so the size is checked from the
+// Content-Length before anything reads the body, and chunked bodies without a length are
+// refused. skip, when set, lets the routes with a limit of their own through.
+func BodyLimit(limit int, skip func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if skip != nil && skip(c) {
+			return c.Next()
+		}
+
+		length := c.Request().Header.ContentLength()
+		if length == -1 {
+			return c.Status(fiber.StatusLengthRequired).JSON(fiber.Map{
+				"error": "Content-Length is required",
+			})
+		}
+		if length > limit {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error": "Request body is too large",
+			})
+		}
+		return c.Next()
+	}
+}
@@ -16,7 +16,7 @@ func Logger(c *fiber.Ctx) error {
 
 	// Log request details
 	log.Printf(
-		"%s %s %s %v",
+		"%s %s %d %v",
 		c.Method(),
 		c.OriginalURL(),
 		c.Response().StatusCode(),